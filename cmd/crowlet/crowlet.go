@@ -1,8 +1,10 @@
 package main
 
 import (
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -75,10 +77,140 @@ func main() {
 			Name:  "crawl-images",
 			Usage: "follow and test image links ('img' tags src)",
 		},
+		cli.BoolFlag{
+			Name: "crawl-stylesheets",
+			Usage: "follow and test stylesheets ('link' tags href with a 'stylesheet' rel)," +
+				" and their imports and url() references",
+		},
+		cli.BoolFlag{
+			Name: "crawl-icons",
+			Usage: "follow and test icons ('link' tags href with an 'icon' or" +
+				" 'apple-touch-icon' rel)",
+		},
+		cli.BoolFlag{
+			Name: "crawl-preloads",
+			Usage: "follow and test preloaded resources ('link' tags href with a" +
+				" 'preload', 'prefetch' or 'modulepreload' rel)",
+		},
+		cli.BoolFlag{
+			Name: "crawl-canonicals",
+			Usage: "follow and test canonical links ('link' tags href with a" +
+				" 'canonical' rel)",
+		},
+		cli.BoolFlag{
+			Name: "crawl-alternates",
+			Usage: "follow and test alternate links ('link' tags href with an" +
+				" 'alternate' rel)",
+		},
+		cli.BoolFlag{
+			Name:  "crawl-scripts",
+			Usage: "follow and test scripts ('script' tags src)",
+		},
+		cli.BoolFlag{
+			Name:  "crawl-iframes",
+			Usage: "follow and test frames ('iframe' tags src)",
+		},
+		cli.BoolFlag{
+			Name: "crawl-media",
+			Usage: "follow and test media ('video' and 'audio' tags and sources" +
+				" src, video poster)",
+		},
+		cli.BoolFlag{
+			Name:  "crawl-objects",
+			Usage: "follow and test objects ('object' tags data)",
+		},
+		cli.BoolFlag{
+			Name:  "crawl-forms",
+			Usage: "follow and test GET forms ('form' tags action)",
+		},
+		cli.BoolFlag{
+			Name: "crawl-refresh",
+			Usage: "follow and test meta refresh targets ('meta' tags with a" +
+				" 'refresh' http-equiv)",
+		},
 		cli.BoolFlag{
 			Name:  "crawl-external",
 			Usage: "follow and test external links. Use in combination with 'follow-hyperlinks' and/or 'follow-images'",
 		},
+		cli.StringSliceFlag{
+			Name: "link-scheme",
+			Usage: "URL scheme of links which can be followed, such as 'https'. Links with" +
+				" 'http' or 'https' schemes are followed if not set",
+		},
+		cli.BoolFlag{
+			Name:  "respect-nofollow",
+			Usage: "do not follow hyperlinks with a 'nofollow' or 'sponsored' rel",
+		},
+		cli.BoolFlag{
+			Name:  "check-contact-links",
+			Usage: "check the syntax of 'mailto' and 'tel' hyperlinks of the crawled pages",
+		},
+		cli.IntFlag{
+			Name:  "contact-link-error",
+			Usage: "error code to use if any 'mailto' or 'tel' hyperlink is invalid",
+			Value: 1,
+		},
+		cli.IntFlag{
+			Name: "max-depth",
+			Usage: "crawl recursively pages of the same origin linked from the" +
+				" sitemap pages, up to the number of links given",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  "max-pages",
+			Usage: "maximum number of pages crawled recursively, including sitemap pages",
+			Value: 0,
+		},
+		cli.StringFlag{
+			Name: "graph-out",
+			Usage: "file to export the link graph of the crawled pages to, as Graphviz DOT" +
+				" (.dot, .gv), GraphML (.graphml) or JSON (.json) depending on its extension",
+		},
+		cli.BoolFlag{
+			Name: "check-canonicals",
+			Usage: "check that canonical links of HTML pages are absolute, return a 200" +
+				" status, do not chain, and point to the page itself for sitemap pages",
+		},
+		cli.IntFlag{
+			Name:  "canonical-error",
+			Usage: "error code to use if any canonical link issue is found",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "audit-seo",
+			Usage: "audit titles, meta descriptions, h1 tags, robots directives and image" +
+				" 'alt' attributes of the sitemap pages",
+		},
+		cli.StringFlag{
+			Name:  "seo-title-length",
+			Usage: "accepted range of page title lengths, in characters",
+			Value: "10-60",
+		},
+		cli.StringFlag{
+			Name:  "seo-description-length",
+			Usage: "accepted range of meta description lengths, in characters",
+			Value: "50-160",
+		},
+		cli.IntFlag{
+			Name:  "seo-error",
+			Usage: "error code to use if any SEO issue is found on a sitemap page",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "check-anchors",
+			Usage: "check that fragments of hyperlinks target an id or a named 'a' tag" +
+				" of the crawled page they link to",
+		},
+		cli.IntFlag{
+			Name:  "anchor-error",
+			Usage: "error code to use if any hyperlink fragment targets a missing anchor",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "coverage-report",
+			Usage: "report sitemap pages no crawled page links to, and pages linked to" +
+				" which are missing from the sitemap",
+		},
 		cli.BoolFlag{
 			Name:  "forever,f",
 			Usage: "crawl the sitemap's URLs forever... or until stopped",
@@ -105,6 +237,47 @@ func main() {
 			Usage: "timeout duration for requests, in milliseconds",
 			Value: 20000,
 		},
+		cli.StringFlag{
+			Name: "method",
+			Usage: "HTTP method to use: GET, HEAD, or HEAD-GET to fall back to" +
+				" GET when HEAD is not allowed",
+			Value: "GET",
+		},
+		cli.StringFlag{
+			Name: "body",
+			Usage: "response body download policy: 'full', 'discard', or a" +
+				" number of bytes to read",
+			Value: "full",
+		},
+		cli.IntFlag{
+			Name: "max-idle-conns-per-host",
+			Usage: "maximum number of idle connections kept alive per host," +
+				" defaults to the throttle value",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name: "idle-conn-timeout",
+			Usage: "duration an idle connection is kept alive, in" +
+				" milliseconds",
+			Value: 90000,
+		},
+		cli.BoolFlag{
+			Name: "disable-keep-alive",
+			Usage: "use a new connection for every request, to measure" +
+				" complete DNS, TCP and TLS times",
+		},
+		cli.BoolFlag{
+			Name:  "cold-iterations",
+			Usage: "close all idle connections between crawling iterations",
+		},
+		cli.BoolFlag{
+			Name:  "disable-http2",
+			Usage: "do not attempt to use HTTP/2",
+		},
+		cli.BoolFlag{
+			Name:  "disable-compression",
+			Usage: "do not request compressed responses",
+		},
 		cli.BoolFlag{
 			Name:  "quiet,silent,q",
 			Usage: "suppress all normal output",
@@ -113,10 +286,25 @@ func main() {
 			Name:  "json,j",
 			Usage: "output using JSON format (experimental)",
 		},
+		cli.StringFlag{
+			Name: "accept",
+			Usage: "comma separated list of accepted status codes, classes or" +
+				" ranges, such as '2xx,304'",
+			Value: "200",
+		},
+		cli.StringFlag{
+			Name: "expectations",
+			Usage: "JSON file mapping URL patterns to their accepted status" +
+				" codes",
+		},
+		cli.BoolFlag{
+			Name:  "no-redirects",
+			Usage: "do not follow redirects, and report their status code",
+		},
 		cli.IntFlag{
 			Name: "non-200-error,e",
-			Usage: "error code to use if any non-200 response if" +
-				" encountered",
+			Usage: "error code to use if any unexpected status code is" +
+				" encountered, i.e. non-200 by default",
 			Value: 1,
 		},
 		cli.IntFlag{
@@ -131,6 +319,164 @@ func main() {
 				" considered an error",
 			Value: 0,
 		},
+		cli.IntFlag{
+			Name:  "dns-max",
+			Usage: "maximum DNS lookup time of requests, in milliseconds, before considered an error",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  "tcp-max",
+			Usage: "maximum TCP connection time of requests, in milliseconds, before considered an error",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  "tls-max",
+			Usage: "maximum TLS handshake time of requests, in milliseconds, before considered an error",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  "ttfb-max",
+			Usage: "maximum time to first byte of requests, in milliseconds, before considered an error",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  "transfer-max",
+			Usage: "maximum content transfer time of requests, in milliseconds, before considered an error",
+			Value: 0,
+		},
+		cli.StringFlag{
+			Name: "policy",
+			Usage: "JSON or YAML file listing rules on crawl metrics, such as response" +
+				" time percentiles or error rates, deciding the exit code instead" +
+				" of the built-in checks and their exit code options",
+		},
+		cli.StringFlag{
+			Name: "groups",
+			Usage: "JSON file defining URL groups, by pattern or path prefix," +
+				" whose statistics are reported separately",
+		},
+		cli.BoolFlag{
+			Name: "group-by-path",
+			Usage: "report statistics separately per first path segment of" +
+				" URLs not matching any group",
+		},
+		cli.StringFlag{
+			Name: "assertions",
+			Usage: "JSON file listing content assertions to check on responses," +
+				" globally or per URL pattern",
+		},
+		cli.StringSliceFlag{
+			Name:  "must-contain",
+			Usage: "text that all response bodies must contain",
+		},
+		cli.StringSliceFlag{
+			Name:  "must-not-contain",
+			Usage: "text that response bodies must not contain",
+		},
+		cli.IntFlag{
+			Name:  "assertion-error",
+			Usage: "error code to use if any content assertion fails",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "detect-soft-404",
+			Usage: "detect pages returning status 200 with the content of the" +
+				" site's not-found page",
+		},
+		cli.Float64Flag{
+			Name: "soft-404-threshold",
+			Usage: "similarity with the not-found page, from 0 to 1, above" +
+				" which a page is considered a soft-404",
+			Value: crawler.DefaultSoft404Threshold,
+		},
+		cli.IntFlag{
+			Name:  "soft-404-error",
+			Usage: "error code to use if any soft-404 page is detected",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "check-certificates",
+			Usage: "report the TLS certificate chain health of each host" +
+				" contacted",
+		},
+		cli.IntFlag{
+			Name: "cert-warn-days",
+			Usage: "minimum number of days before certificates expire, before" +
+				" considered an error. Implies 'check-certificates'",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  "cert-error",
+			Usage: "error code to use if any certificate issue is found",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "audit-security-headers",
+			Usage: "check HTML pages for missing security headers and insecure" +
+				" cookies",
+		},
+		cli.StringSliceFlag{
+			Name: "required-header",
+			Usage: "security header required on HTML pages, replacing the" +
+				" default list when set",
+		},
+		cli.IntFlag{
+			Name:  "security-error",
+			Usage: "error code to use if any security header issue is found",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "check-mixed-content",
+			Usage: "report images, scripts, stylesheets, frames and media loaded over http" +
+				" by https pages, as active or passive mixed content",
+		},
+		cli.IntFlag{
+			Name:  "mixed-content-error",
+			Usage: "error code to use if any https page loads http resources",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "cache-report",
+			Usage: "record caching headers and report CDN cache hit ratios per" +
+				" iteration",
+		},
+		cli.StringFlag{
+			Name:  "cache-status-header",
+			Usage: "response header holding the CDN cache status, such as 'CF-Cache-Status'",
+			Value: crawler.DefaultCacheStatusHeader,
+		},
+		cli.BoolFlag{
+			Name: "check-revalidation",
+			Usage: "request pages again with their ETag and Last-Modified" +
+				" validators, and expect a 304 status code",
+		},
+		cli.IntFlag{
+			Name:  "revalidation-error",
+			Usage: "error code to use if any page does not support revalidation",
+			Value: 1,
+		},
+		cli.BoolFlag{
+			Name: "compression-report",
+			Usage: "record the encoding and transfer size of responses, and" +
+				" report compression ratios per content type",
+		},
+		cli.Int64Flag{
+			Name: "compression-min-size",
+			Usage: "size in bytes above which text responses must be compressed." +
+				" Implies --compression-report",
+			Value: 0,
+		},
+		cli.IntFlag{
+			Name:  "compression-error",
+			Usage: "error code to use if any text response is not compressed",
+			Value: 1,
+		},
+		cli.StringSliceFlag{
+			Name: "variant",
+			Usage: "request header values to crawl each URL with, such as" +
+				" 'Accept-Encoding=gzip|br'. URLs are crawled once per combination" +
+				" of all variant headers",
+		},
 		cli.BoolFlag{
 			Name:  "summary-only",
 			Usage: "print only the summary",
@@ -183,14 +529,19 @@ func addInterruptHandlers() chan struct{} {
 	return stop
 }
 
-func runMainLoop(urls []string, config crawler.CrawlConfig, iterations int, forever bool, waitInterval int) (stats crawler.CrawlStats) {
+func runMainLoop(urls []string, crawl *crawler.Crawler, iterations int, forever bool, waitInterval int,
+	coldIterations bool) (stats crawler.CrawlStats) {
 	for i := 0; i < iterations || forever; i++ {
 		if i != 0 {
 			time.Sleep(time.Duration(waitInterval) * time.Second)
+
+			if coldIterations {
+				crawl.CloseIdleConnections()
+			}
 		}
 
 		quit := addInterruptHandlers()
-		itStats, err := crawler.AsyncCrawl(urls, config, quit)
+		itStats, err := crawl.Crawl(urls, quit)
 
 		stats = crawler.MergeCrawlStats(stats, itStats)
 
@@ -209,6 +560,59 @@ func runMainLoop(urls []string, config crawler.CrawlConfig, iterations int, fore
 	return
 }
 
+func loadAssertions(c *cli.Context) (assertions []crawler.Assertion, err error) {
+	if c.String("assertions") != "" {
+		assertions, err = crawler.LoadAssertions(c.String("assertions"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.StringSlice("must-contain")) > 0 || len(c.StringSlice("must-not-contain")) > 0 {
+		assertions = append(assertions, crawler.Assertion{
+			Contains:    c.StringSlice("must-contain"),
+			NotContains: c.StringSlice("must-not-contain"),
+		})
+	}
+
+	return
+}
+
+func loadStatusPolicy(c *cli.Context) (policy crawler.StatusPolicy, err error) {
+	policy.Accept, err = crawler.ParseStatusSet(c.String("accept"))
+	if err != nil {
+		return
+	}
+
+	if c.String("expectations") != "" {
+		policy.Expectations, err = crawler.LoadStatusExpectations(c.String("expectations"))
+	}
+
+	return
+}
+
+func loadGroupPolicy(c *cli.Context) (policy crawler.GroupPolicy, err error) {
+	policy.ByPath = c.Bool("group-by-path")
+	if c.String("groups") != "" {
+		policy.Groups, err = crawler.LoadURLGroups(c.String("groups"))
+	}
+
+	return
+}
+
+func loadVariants(c *cli.Context) ([]crawler.Variant, error) {
+	var headers []crawler.VariantHeader
+	for _, value := range c.StringSlice("variant") {
+		header, err := crawler.ParseVariantHeader(value)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+
+	return crawler.VariantMatrix(headers), nil
+}
+
 func start(c *cli.Context) error {
 	sitemapURL := c.Args().Get(0)
 	log.Info("Crawling ", sitemapURL)
@@ -219,13 +623,100 @@ func start(c *cli.Context) error {
 	}
 	log.Info("Found ", len(urls), " URL(s)")
 
+	method, err := crawler.ParseMethod(c.String("method"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bodyPolicy, bodyLimit, err := crawler.ParseBodyPolicy(c.String("body"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	assertions, err := loadAssertions(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	statusPolicy, err := loadStatusPolicy(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	variants, err := loadVariants(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	groupPolicy, err := loadGroupPolicy(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var policy []crawler.PolicyRule
+	if c.String("policy") != "" {
+		policy, err = crawler.LoadPolicy(c.String("policy"))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	graphOut := c.String("graph-out")
+	if graphOut != "" {
+		_, err = crawler.GraphFormatFromPath(graphOut)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	titleLength, err := crawler.ParseLengthRange(c.String("seo-title-length"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	descriptionLength, err := crawler.ParseLengthRange(c.String("seo-description-length"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	crawlLinks := c.Int("max-depth") > 0 || c.Bool("coverage-report") || c.Bool("check-anchors") ||
+		c.Bool("check-contact-links") || c.Bool("check-mixed-content") || graphOut != "" ||
+		c.Bool("check-canonicals") || c.Bool("audit-seo")
+	for _, flag := range c.GlobalFlagNames() {
+		if strings.HasPrefix(flag, "crawl-") && c.Bool(flag) {
+			crawlLinks = true
+		}
+	}
+	if crawlLinks && (method == http.MethodHead || bodyPolicy == crawler.DiscardBody) {
+		log.Warn("Links can not be crawled without downloading page bodies")
+	}
+
 	config := crawler.CrawlConfig{
 		Throttle: c.Int("throttle"),
 		Host:     c.String("override-host"),
 		HTTP: crawler.HTTPConfig{
-			User:    c.String("user"),
-			Pass:    c.String("pass"),
-			Timeout: time.Duration(c.Int("timeout")) * time.Millisecond,
+			User:        c.String("user"),
+			Pass:        c.String("pass"),
+			Timeout:     time.Duration(c.Int("timeout")) * time.Millisecond,
+			Method:      method,
+			Body:        bodyPolicy,
+			BodyLimit:   bodyLimit,
+			Assertions:  assertions,
+			NoRedirects: c.Bool("no-redirects"),
+
+			AuditSecurity:   c.Bool("audit-security-headers"),
+			RequiredHeaders: c.StringSlice("required-header"),
+
+			CheckMixedContent: c.Bool("check-mixed-content"),
+			CheckCanonicals:   c.Bool("check-canonicals"),
+			AuditSEO:          c.Bool("audit-seo"),
+
+			CacheReport:       c.Bool("cache-report"),
+			CacheStatusHeader: c.String("cache-status-header"),
+
+			CheckRevalidation: c.Bool("check-revalidation"),
+
+			CompressionReport:  c.Bool("compression-report") || c.Int64("compression-min-size") > 0,
+			CompressionMinSize: c.Int64("compression-min-size"),
 		},
 		HTTPGetter: &crawler.BaseConcurrentHTTPGetter{
 			Get: crawler.HTTPGet,
@@ -234,10 +725,80 @@ func start(c *cli.Context) error {
 			CrawlExternalLinks: c.Bool("crawl-external"),
 			CrawlImages:        c.Bool("crawl-images"),
 			CrawlHyperlinks:    c.Bool("crawl-hyperlinks"),
+			CrawlStylesheets:   c.Bool("crawl-stylesheets"),
+			CrawlIcons:         c.Bool("crawl-icons"),
+			CrawlPreloads:      c.Bool("crawl-preloads"),
+			CrawlCanonicals:    c.Bool("crawl-canonicals"),
+			CrawlAlternates:    c.Bool("crawl-alternates"),
+			CrawlScripts:       c.Bool("crawl-scripts"),
+			CrawlFrames:        c.Bool("crawl-iframes"),
+			CrawlMedia:         c.Bool("crawl-media"),
+			CrawlObjects:       c.Bool("crawl-objects"),
+			CrawlForms:         c.Bool("crawl-forms"),
+			CrawlRefreshes:     c.Bool("crawl-refresh"),
+
+			Schemes:         c.StringSlice("link-scheme"),
+			RespectNoFollow: c.Bool("respect-nofollow"),
+		},
+		Spider: crawler.SpiderConfig{
+			MaxDepth: c.Int("max-depth"),
+			MaxPages: c.Int("max-pages"),
+		},
+		Transport: crawler.TransportConfig{
+			MaxIdleConnsPerHost: c.Int("max-idle-conns-per-host"),
+			IdleConnTimeout:     time.Duration(c.Int("idle-conn-timeout")) * time.Millisecond,
+			DisableKeepAlives:   c.Bool("disable-keep-alive"),
+			DisableHTTP2:        c.Bool("disable-http2"),
+			DisableCompression:  c.Bool("disable-compression"),
+		},
+		DetectSoft404:    c.Bool("detect-soft-404"),
+		Soft404Threshold: c.Float64("soft-404-threshold"),
+		Status:           statusPolicy,
+		Variants:         variants,
+		Groups:           groupPolicy,
+		Coverage:         c.Bool("coverage-report"),
+		CheckAnchors:     c.Bool("check-anchors"),
+
+		CheckContactLinks: c.Bool("check-contact-links"),
+		Graph:             graphOut != "",
+		SEO: crawler.SEOConfig{
+			TitleLength:       titleLength,
+			DescriptionLength: descriptionLength,
 		},
+
+		CheckCertificates:   c.Bool("check-certificates") || c.Int("cert-warn-days") > 0,
+		CertificateWarnDays: c.Int("cert-warn-days"),
+	}
+	if len(policy) > 0 {
+		config, err = crawler.EnablePolicyChecks(policy, config)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// The policy decides the exit code instead of the built-in checks
+		for _, flag := range c.GlobalFlagNames() {
+			if (strings.HasSuffix(flag, "-error") || strings.HasSuffix(flag, "-max")) && c.IsSet(flag) {
+				log.Warn("Option --", flag, " is ignored, the exit code being decided by the policy")
+			}
+		}
+	}
+	if err = config.HTTP.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	stats := runMainLoop(urls, crawler.NewCrawler(config), c.Int("iterations"), c.Bool("forever"),
+		c.Int("wait-interval"), c.Bool("cold-iterations"))
+	if len(policy) > 0 {
+		stats.PolicyResults = crawler.EvaluatePolicy(policy, stats)
+	}
+
+	if graphOut != "" {
+		err = crawler.WriteLinkGraph(graphOut, stats.Graph)
+		if err != nil {
+			log.Error("Failed to export link graph: ", err)
+		}
 	}
 
-	stats := runMainLoop(urls, config, c.Int("iterations"), c.Bool("forever"), c.Int("wait-interval"))
 	if !c.GlobalBool("quiet") {
 		if c.GlobalBool("json") {
 			crawler.PrintJSONSummary(stats)
@@ -250,16 +811,55 @@ func start(c *cli.Context) error {
 		}
 	}
 
-	if stats.Total != stats.StatusCodes[200] {
-		exitCode = c.Int("non-200-error")
+	if len(policy) > 0 {
+		exitCode = crawler.PolicyExitCode(stats.PolicyResults)
 		return nil
 	}
 
+	// The exit code is the one of the first failed check, status codes
+	// taking precedence
+	checks := []struct {
+		failed bool
+		flag   string
+	}{
+		{len(stats.UnexpectedStatuses) > 0, "non-200-error"},
+		{len(stats.AssertionFailures) > 0, "assertion-error"},
+		{len(stats.Soft404Urls) > 0, "soft-404-error"},
+		{stats.CertificateIssues() > 0, "cert-error"},
+		{len(stats.SecurityIssues) > 0, "security-error"},
+		{len(stats.MixedContent) > 0, "mixed-content-error"},
+		{len(stats.RevalidationIssues) > 0, "revalidation-error"},
+		{len(stats.BrokenAnchors) > 0, "anchor-error"},
+		{len(stats.ContactLinkIssues) > 0, "contact-link-error"},
+		{len(stats.CanonicalIssues) > 0, "canonical-error"},
+		{stats.SEOIssueCount() > 0, "seo-error"},
+		{len(stats.CompressionIssues) > 0, "compression-error"},
+	}
+	for _, check := range checks {
+		if check.failed {
+			exitCode = c.Int(check.flag)
+			return nil
+		}
+	}
+
 	maxResponseTime := c.Int("response-time-max")
 	if maxResponseTime > 0 && int(stats.Max200Time/time.Millisecond) > maxResponseTime {
 		log.Warn("Max response time (", maxResponseTime, "ms) was exceeded")
 		exitCode = c.Int("response-time-error")
 	}
 
+	for _, phase := range crawler.Phases {
+		maxPhaseTime := c.Int(phase + "-max")
+		if maxPhaseTime > 0 && int(stats.PhaseTimes[phase].Max/time.Millisecond) > maxPhaseTime {
+			log.Warn("Max ", phase, " time (", maxPhaseTime, "ms) was exceeded")
+			exitCode = c.Int("response-time-error")
+		}
+	}
+
+	for _, group := range groupPolicy.SlowGroups(stats.Groups) {
+		log.Warn("Max response time of group ", group.Name, " (", group.MaxTimeMs, "ms) was exceeded")
+		exitCode = c.Int("response-time-error")
+	}
+
 	return nil
 }
@@ -1,11 +1,21 @@
 module github.com/Pixep/crowlet
 
-go 1.14
+go 1.20
 
 require (
 	github.com/PuerkitoBio/goquery v1.9.3
+	github.com/andybalholm/brotli v1.1.1
 	github.com/sirupsen/logrus v1.9.3
 	github.com/tcnksm/go-httpstat v0.2.1-0.20191008022543-e866bb274419
 	github.com/urfave/cli v1.22.16
 	github.com/yterajima/go-sitemap v0.4.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/andybalholm/cascadia v1.3.2 // indirect
+	github.com/cpuguy83/go-md2man/v2 v2.0.5 // indirect
+	github.com/russross/blackfriday/v2 v2.1.0 // indirect
+	golang.org/x/net v0.29.0 // indirect
+	golang.org/x/sys v0.25.0 // indirect
 )
@@ -0,0 +1,74 @@
+package crawler
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// documentAnchors returns the fragments which can be targeted in the html
+// document: element ids, and names of 'a' tags
+func documentAnchors(doc *goquery.Document) map[string]bool {
+	anchors := make(map[string]bool)
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		id, _ := s.Attr("id")
+		anchors[id] = true
+	})
+	doc.Find("a[name]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		anchors[name] = true
+	})
+
+	return anchors
+}
+
+// hasAnchor returns whether the fragment passed targets an anchor of the
+// page. Empty and 'top' fragments always target the top of the page, and
+// fragments starting with '/' or '!' are routes of single-page applications
+// rather than anchors.
+func hasAnchor(anchors map[string]bool, fragment string) bool {
+	return fragment == "" || strings.EqualFold(fragment, "top") || strings.HasPrefix(fragment, "/") ||
+		strings.HasPrefix(fragment, "!") || anchors[fragment]
+}
+
+// brokenAnchors returns the hyperlinks of the crawled pages whose fragment
+// does not target any anchor of the linked page, along with the pages linking
+// to them. Links to pages which were not crawled, or are not html, are
+// ignored.
+func brokenAnchors(results map[string]*HTTPResponse) (broken []CrawlResult) {
+	linkingURLs := make(map[string][]string)
+	reports := make(map[string]CrawlResult)
+	for _, result := range results {
+		for _, link := range result.Links {
+			if link.Type != Hyperlink || link.TargetURL.Fragment == "" {
+				continue
+			}
+
+			targetResult, ok := results[link.pageURL()]
+			if !ok || targetResult.Anchors == nil ||
+				hasAnchor(targetResult.Anchors, link.TargetURL.Fragment) {
+				continue
+			}
+
+			linkURL := link.TargetURL.String()
+			linkingURLs[linkURL] = appendUnique(linkingURLs[linkURL], result.URL)
+			reports[linkURL] = CrawlResult{
+				URL:        linkURL,
+				StatusCode: targetResult.StatusCode,
+				Issues:     []string{"anchor '" + link.TargetURL.Fragment + "' not found"},
+			}
+		}
+	}
+
+	for linkURL, report := range reports {
+		report.LinkingURLs = linkingURLs[linkURL]
+		sort.Strings(report.LinkingURLs)
+		broken = append(broken, report)
+	}
+	sort.Slice(broken, func(i, j int) bool {
+		return broken[i].URL < broken[j].URL
+	})
+
+	return
+}
@@ -0,0 +1,74 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAsyncCrawlCheckAnchors(t *testing.T) {
+	pages := map[string]string{
+		"/": `<a href="#intro">Intro</a><a href="#missing">Missing</a><a href="#top">Top</a><a href="#">Top</a>
+			<h2 id="intro">Intro</h2><a href="/docs#install">Install</a><a href="/docs#removed">Removed</a>`,
+		"/docs": `<a name="install"></a><a href="/#missing">Missing</a><a href="/faq#question">FAQ</a>
+			<a href="/guide#anything">Guide</a><a href="/faq#/questions/1">Route</a><a href="/faq#!/answers">Route</a>`,
+		"/faq": `<p id="answer">Answer</p>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>", page, "</body></html>")
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:     2,
+		HTTP:         HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter:   &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		CheckAnchors: true,
+	}
+
+	stats, _ := AsyncCrawl([]string{server.URL + "/", server.URL + "/docs"}, config, make(chan struct{}))
+
+	expected := []struct {
+		url     string
+		linking []string
+	}{
+		{"/#missing", []string{"/", "/docs"}},
+		{"/docs#removed", []string{"/"}},
+	}
+	if len(stats.BrokenAnchors) != len(expected) {
+		t.Fatalf("expected %d broken anchors, got %+v", len(expected), stats.BrokenAnchors)
+	}
+	for i, broken := range stats.BrokenAnchors {
+		if broken.URL != server.URL+expected[i].url || len(broken.LinkingURLs) != len(expected[i].linking) {
+			t.Errorf("unexpected broken anchor report %+v", broken)
+			continue
+		}
+		for j, linkingURL := range broken.LinkingURLs {
+			if linkingURL != server.URL+expected[i].linking[j] {
+				t.Errorf("unexpected linking URL %s for %s", linkingURL, broken.URL)
+			}
+		}
+	}
+	if stats.Total != 2 {
+		t.Errorf("expected same-page links not to be crawled, got %d pages", stats.Total)
+	}
+
+	if merged := MergeCrawlStats(stats, stats); len(merged.BrokenAnchors) != len(expected) {
+		t.Errorf("expected broken anchors to be listed once across iterations, got %+v", merged.BrokenAnchors)
+	}
+
+	config.Links.CrawlHyperlinks = true
+	stats, _ = AsyncCrawl([]string{server.URL + "/", server.URL + "/docs"}, config, make(chan struct{}))
+	if len(stats.BrokenAnchors) != 3 || stats.BrokenAnchors[2].URL != server.URL+"/faq#question" {
+		t.Errorf("expected anchors of linked pages to be checked, got %+v", stats.BrokenAnchors)
+	}
+}
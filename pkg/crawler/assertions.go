@@ -0,0 +1,190 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Assertion holds checks performed on the responses whose URL matches
+// Pattern, a regular expression. An empty Pattern matches all URLs.
+type Assertion struct {
+	Pattern     string            `json:"pattern"`
+	Contains    []string          `json:"contains"`
+	NotContains []string          `json:"not-contains"`
+	Matches     []string          `json:"matches"`
+	NotMatches  []string          `json:"not-matches"`
+	Selectors   []string          `json:"selectors"`
+	ContentType string            `json:"content-type"`
+	Headers     map[string]string `json:"headers"`
+	MinSize     int64             `json:"min-size"`
+	MaxSize     int64             `json:"max-size"`
+
+	pattern    *regexp.Regexp
+	matches    []*regexp.Regexp
+	notMatches []*regexp.Regexp
+	headers    map[string]*regexp.Regexp
+}
+
+// Compile validates the assertion and compiles its regular expressions. It
+// must be called before the assertion is used.
+func (assertion *Assertion) Compile() (err error) {
+	if assertion.Pattern != "" {
+		assertion.pattern, err = regexp.Compile(assertion.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid assertion pattern '%s': %v", assertion.Pattern, err)
+		}
+	}
+
+	assertion.matches, err = compileRegexps(assertion.Matches)
+	if err != nil {
+		return err
+	}
+
+	assertion.notMatches, err = compileRegexps(assertion.NotMatches)
+	if err != nil {
+		return err
+	}
+
+	assertion.headers = make(map[string]*regexp.Regexp)
+	for name, value := range assertion.Headers {
+		assertion.headers[name], err = regexp.Compile(value)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression for header '%s': %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+func compileRegexps(expressions []string) (regexps []*regexp.Regexp, err error) {
+	for _, expression := range expressions {
+		compiled, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression '%s': %v", expression, err)
+		}
+		regexps = append(regexps, compiled)
+	}
+
+	return
+}
+
+// LoadAssertions reads and compiles a list of assertions from a JSON file
+func LoadAssertions(path string) (assertions []Assertion, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &assertions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid assertions file '%s': %v", path, err)
+	}
+
+	for i := range assertions {
+		err = assertions[i].Compile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// NeedsBody returns whether the assertion checks the response body, which
+// must then be downloaded
+func (assertion *Assertion) NeedsBody() bool {
+	return len(assertion.Contains) > 0 || len(assertion.NotContains) > 0 || len(assertion.Matches) > 0 ||
+		len(assertion.NotMatches) > 0 || len(assertion.Selectors) > 0 || assertion.MinSize > 0 ||
+		assertion.MaxSize > 0
+}
+
+// Applies returns whether the assertion should be checked for the URL
+func (assertion *Assertion) Applies(url string) bool {
+	return assertion.pattern == nil || assertion.pattern.MatchString(url)
+}
+
+func (assertion *Assertion) check(header http.Header, body *page, size int64) (failures []string) {
+	for _, text := range assertion.Contains {
+		if !bytes.Contains(body.content, []byte(text)) {
+			failures = append(failures, fmt.Sprintf("body does not contain '%s'", text))
+		}
+	}
+
+	for _, text := range assertion.NotContains {
+		if bytes.Contains(body.content, []byte(text)) {
+			failures = append(failures, fmt.Sprintf("body contains '%s'", text))
+		}
+	}
+
+	for _, expression := range assertion.matches {
+		if !expression.Match(body.content) {
+			failures = append(failures, fmt.Sprintf("body does not match '%s'", expression))
+		}
+	}
+
+	for _, expression := range assertion.notMatches {
+		if expression.Match(body.content) {
+			failures = append(failures, fmt.Sprintf("body matches '%s'", expression))
+		}
+	}
+
+	if len(assertion.Selectors) > 0 {
+		doc, err := body.document()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("body can not be parsed as HTML: %v", err))
+		} else {
+			for _, selector := range assertion.Selectors {
+				if doc.Find(selector).Length() == 0 {
+					failures = append(failures, fmt.Sprintf("no element matches selector '%s'", selector))
+				}
+			}
+		}
+	}
+
+	if assertion.ContentType != "" {
+		contentType := header.Get("Content-Type")
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !strings.EqualFold(mediaType, assertion.ContentType) {
+			failures = append(failures, fmt.Sprintf("content type is '%s' instead of '%s'",
+				contentType, assertion.ContentType))
+		}
+	}
+
+	for name, expression := range assertion.headers {
+		values, ok := header[http.CanonicalHeaderKey(name)]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("header '%s' is missing", name))
+		} else if !expression.MatchString(strings.Join(values, ", ")) {
+			failures = append(failures, fmt.Sprintf("header '%s' does not match '%s'", name, expression))
+		}
+	}
+
+	if assertion.MinSize > 0 && size < assertion.MinSize {
+		failures = append(failures, fmt.Sprintf("body size %d is below %d bytes", size, assertion.MinSize))
+	}
+
+	if assertion.MaxSize > 0 && size > assertion.MaxSize {
+		failures = append(failures, fmt.Sprintf("body size %d is above %d bytes", size, assertion.MaxSize))
+	}
+
+	return
+}
+
+// checkAssertions evaluates all assertions applying to the URL against the
+// response headers and body provided, and returns the failures found
+func checkAssertions(assertions []Assertion, url string, header http.Header, body *page,
+	size int64) (failures []string) {
+	for i := range assertions {
+		if assertions[i].Applies(url) {
+			failures = append(failures, assertions[i].check(header, body, size)...)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,135 @@
+package crawler
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckAssertions(t *testing.T) {
+	body := []byte(`<html><body><div id="content">Welcome home</div></body></html>`)
+	header := http.Header{}
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Set("Cache-Control", "max-age=60")
+
+	tests := []struct {
+		name             string
+		assertion        Assertion
+		url              string
+		expectedFailures int
+	}{
+		{
+			name:             "Contains and not contains",
+			assertion:        Assertion{Contains: []string{"Welcome"}, NotContains: []string{"Something went wrong"}},
+			expectedFailures: 0,
+		},
+		{
+			name:             "Error template",
+			assertion:        Assertion{Contains: []string{"Goodbye"}, NotContains: []string{"home"}},
+			expectedFailures: 2,
+		},
+		{
+			name:             "Regular expressions",
+			assertion:        Assertion{Matches: []string{`Welcome\s+\w+`}, NotMatches: []string{`(?i)error`}},
+			expectedFailures: 0,
+		},
+		{
+			name:             "Selectors",
+			assertion:        Assertion{Selectors: []string{"div#content", "footer"}},
+			expectedFailures: 1,
+		},
+		{
+			name:             "Content type",
+			assertion:        Assertion{ContentType: "application/json"},
+			expectedFailures: 1,
+		},
+		{
+			name:             "Headers",
+			assertion:        Assertion{Headers: map[string]string{"cache-control": "max-age", "ETag": ""}},
+			expectedFailures: 1,
+		},
+		{
+			name:             "Body size",
+			assertion:        Assertion{MinSize: 10, MaxSize: 20},
+			expectedFailures: 1,
+		},
+		{
+			name:             "Pattern not matching",
+			assertion:        Assertion{Pattern: "/products/", Contains: []string{"Goodbye"}},
+			url:              "http://example.com/blog/post",
+			expectedFailures: 0,
+		},
+		{
+			name:             "Pattern matching",
+			assertion:        Assertion{Pattern: "/products/", Contains: []string{"Goodbye"}},
+			url:              "http://example.com/products/1",
+			expectedFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.assertion.Compile(); err != nil {
+				t.Fatal("unexpected compilation error:", err)
+			}
+
+			url := tt.url
+			if url == "" {
+				url = "http://example.com/"
+			}
+
+			failures := checkAssertions([]Assertion{tt.assertion}, url, header, &page{content: body},
+				int64(len(body)))
+			if len(failures) != tt.expectedFailures {
+				t.Errorf("expected %d failures, got %v", tt.expectedFailures, failures)
+			}
+		})
+	}
+}
+
+func TestLoadAssertions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "assertions.json")
+	content := `[{"pattern": "/products/", "selectors": [".price"]}, {"not-contains": ["Something went wrong"]}]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	assertions, err := LoadAssertions(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if len(assertions) != 2 {
+		t.Fatalf("expected 2 assertions, got %d", len(assertions))
+	}
+	if assertions[0].Applies("http://example.com/blog/") || !assertions[1].Applies("http://example.com/blog/") {
+		t.Errorf("assertion patterns not applied as expected")
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"matches": ["("]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAssertions(path); err == nil {
+		t.Errorf("expected an error for an invalid regular expression")
+	}
+}
+
+func TestAssertionNeedsBody(t *testing.T) {
+	if (&Assertion{ContentType: "text/html", Headers: map[string]string{"Cache-Control": "max-age"}}).NeedsBody() {
+		t.Errorf("expected header assertions not to need the body")
+	}
+	for _, assertion := range []Assertion{{Contains: []string{"foo"}}, {Selectors: []string{"h1"}}, {MaxSize: 10}} {
+		if !assertion.NeedsBody() {
+			t.Errorf("expected assertion %+v to need the body", assertion)
+		}
+	}
+}
+
+func TestMergeAssertionFailures(t *testing.T) {
+	failure := CrawlResult{URL: "http://example.com/", Issues: []string{"body contains 'error'"}}
+	stats := CrawlStats{AssertionFailures: []CrawlResult{failure}}
+	if merged := MergeCrawlStats(stats, stats); len(merged.AssertionFailures) != 1 {
+		t.Errorf("expected assertion failures to be listed once across iterations, got %+v",
+			merged.AssertionFailures)
+	}
+}
@@ -0,0 +1,134 @@
+package crawler
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultCacheStatusHeader is the response header holding the CDN cache
+// status, unless configured otherwise
+const DefaultCacheStatusHeader = "X-Cache"
+
+// CDN cache results, as classified from the cache status header
+const (
+	CacheHit     = "hit"
+	CacheMiss    = "miss"
+	CacheBypass  = "bypass"
+	CacheUnknown = "unknown"
+)
+
+// CacheInfo holds the caching related headers of a response
+type CacheInfo struct {
+	CacheControl string `json:"cache-control,omitempty"`
+	Age          string `json:"age,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last-modified,omitempty"`
+	Vary         string `json:"vary,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Result       string `json:"result"`
+	SetsCookie   bool   `json:"sets-cookie"`
+}
+
+// CacheStats holds the CDN cache results of a crawling iteration
+type CacheStats struct {
+	Hits     int `json:"hit"`
+	Misses   int `json:"miss"`
+	Bypasses int `json:"bypass"`
+	Unknown  int `json:"unknown"`
+}
+
+// ParseCacheInfo extracts caching information from response headers, using
+// statusHeader as the CDN cache status header
+func ParseCacheInfo(header http.Header, statusHeader string) CacheInfo {
+	if statusHeader == "" {
+		statusHeader = DefaultCacheStatusHeader
+	}
+
+	info := CacheInfo{
+		CacheControl: header.Get("Cache-Control"),
+		Age:          header.Get("Age"),
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Vary:         strings.Join(header.Values("Vary"), ", "),
+		Status:       strings.Join(header.Values(statusHeader), ", "),
+		SetsCookie:   len(header.Values("Set-Cookie")) > 0,
+	}
+	info.Result = classifyCacheStatus(info.Status)
+
+	return info
+}
+
+// classifyCacheStatus converts a CDN cache status such as "TCP_HIT" or
+// "MISS, HIT" to a cache result. Only the last value of a list is considered,
+// as it is set by the cache closest to the client.
+func classifyCacheStatus(status string) string {
+	values := strings.Split(status, ",")
+	value := strings.ToUpper(strings.TrimSpace(values[len(values)-1]))
+
+	switch {
+	case value == "":
+		return CacheUnknown
+	case strings.Contains(value, "MISS") || strings.Contains(value, "EXPIRED"):
+		return CacheMiss
+	case strings.Contains(value, "HIT") || strings.Contains(value, "STALE") ||
+		strings.Contains(value, "REVALIDATED") || strings.Contains(value, "UPDATING"):
+		return CacheHit
+	case strings.Contains(value, "BYPASS") || strings.Contains(value, "PASS") ||
+		strings.Contains(value, "DYNAMIC"):
+		return CacheBypass
+	}
+
+	return CacheUnknown
+}
+
+// UncacheableReasons returns why the response can not be cached by shared
+// caches, if it can not
+func (info CacheInfo) UncacheableReasons() (reasons []string) {
+	for _, directive := range strings.Split(strings.ToLower(info.CacheControl), ",") {
+		directive = strings.TrimSpace(directive)
+		if directive == "no-store" || directive == "private" || strings.HasPrefix(directive, "private=") {
+			reasons = append(reasons, "Cache-Control: "+directive)
+		}
+	}
+
+	if info.SetsCookie {
+		reasons = append(reasons, "response sets a cookie")
+	}
+
+	return
+}
+
+func (stats *CacheStats) add(result string) {
+	switch result {
+	case CacheHit:
+		stats.Hits++
+	case CacheMiss:
+		stats.Misses++
+	case CacheBypass:
+		stats.Bypasses++
+	default:
+		stats.Unknown++
+	}
+}
+
+func (stats CacheStats) merge(other CacheStats) CacheStats {
+	return CacheStats{
+		Hits:     stats.Hits + other.Hits,
+		Misses:   stats.Misses + other.Misses,
+		Bypasses: stats.Bypasses + other.Bypasses,
+		Unknown:  stats.Unknown + other.Unknown,
+	}
+}
+
+// Total returns the number of responses counted
+func (stats CacheStats) Total() int {
+	return stats.Hits + stats.Misses + stats.Bypasses + stats.Unknown
+}
+
+// HitRatio returns the ratio of cache hits, from 0 to 1
+func (stats CacheStats) HitRatio() float64 {
+	if stats.Total() == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(stats.Total())
+}
@@ -0,0 +1,107 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassifyCacheStatus(t *testing.T) {
+	tests := map[string]string{
+		"":                     CacheUnknown,
+		"HIT":                  CacheHit,
+		"TCP_MEM_HIT":          CacheHit,
+		"MISS, HIT":            CacheHit,
+		"HIT, MISS":            CacheMiss,
+		"Miss from cloudfront": CacheMiss,
+		"EXPIRED":              CacheMiss,
+		"REVALIDATED":          CacheHit,
+		"BYPASS":               CacheBypass,
+		"DYNAMIC":              CacheBypass,
+		"something":            CacheUnknown,
+	}
+
+	for status, expected := range tests {
+		if result := classifyCacheStatus(status); result != expected {
+			t.Errorf("expected '%s' to be classified as %s, got %s", status, expected, result)
+		}
+	}
+}
+
+func TestUncacheableReasons(t *testing.T) {
+	header := http.Header{}
+	header.Set("Cache-Control", "private, no-store, max-age=0")
+	header.Set("Set-Cookie", "session=1")
+
+	if reasons := ParseCacheInfo(header, "").UncacheableReasons(); len(reasons) != 3 {
+		t.Errorf("expected 3 reasons, got %v", reasons)
+	}
+
+	header = http.Header{}
+	header.Set("Cache-Control", "public, max-age=600")
+	if reasons := ParseCacheInfo(header, "").UncacheableReasons(); len(reasons) != 0 {
+		t.Errorf("expected no reasons, got %v", reasons)
+	}
+}
+
+func TestCacheReportPerIteration(t *testing.T) {
+	var mutex sync.Mutex
+	cached := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if r.URL.Path == "/account" {
+			w.Header().Set("Cache-Control", "private")
+			w.Header().Set("CF-Cache-Status", "BYPASS")
+		} else if cached[r.URL.Path] {
+			w.Header().Set("CF-Cache-Status", "HIT")
+		} else {
+			w.Header().Set("CF-Cache-Status", "MISS")
+		}
+		cached[r.URL.Path] = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	crawler := NewCrawler(CrawlConfig{
+		Throttle: 2,
+		HTTP: HTTPConfig{
+			Timeout:           5 * time.Second,
+			CacheReport:       true,
+			CacheStatusHeader: "CF-Cache-Status",
+		},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+	})
+
+	var stats CrawlStats
+	urls := []string{server.URL + "/a", server.URL + "/b", server.URL + "/account"}
+	for i := 0; i < 2; i++ {
+		itStats, _ := crawler.Crawl(urls, make(chan struct{}))
+		stats = MergeCrawlStats(stats, itStats)
+	}
+
+	if len(stats.CacheIterations) != 2 {
+		t.Fatalf("expected 2 cache iterations, got %d", len(stats.CacheIterations))
+	}
+
+	first, second := stats.CacheIterations[0], stats.CacheIterations[1]
+	if first.Misses != 2 || first.Bypasses != 1 || first.Hits != 0 {
+		t.Errorf("unexpected first iteration results: %+v", first)
+	}
+	if second.Hits != 2 || second.Bypasses != 1 || second.Misses != 0 {
+		t.Errorf("unexpected second iteration results: %+v", second)
+	}
+	if len(stats.UncacheableUrls) != 1 || stats.UncacheableUrls[0].URL != server.URL+"/account" {
+		t.Errorf("expected account page to be reported uncacheable once, got %v", stats.UncacheableUrls)
+	}
+
+	if info := stats.CacheHeaders[server.URL+"/a"]; info.Status != "HIT" || info.Result != CacheHit {
+		t.Errorf("expected headers of the last response to be kept, got %+v", info)
+	}
+	if info := stats.CacheHeaders[server.URL+"/account"]; info.CacheControl != "private" {
+		t.Errorf("unexpected cache headers %+v", info)
+	}
+}
@@ -0,0 +1,206 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+)
+
+// CanonicalLink is a 'link' tag of a page with a 'canonical' relation
+type CanonicalLink struct {
+	// Href is the URL as written in the page
+	Href string
+	// URL is the URL resolved against the page base URL, without fragment
+	URL string
+}
+
+// documentCanonicals returns the canonical links of the html document
+func documentCanonicals(doc *goquery.Document, currentURL url.URL) (canonicals []CanonicalLink) {
+	baseURL := documentBaseURL(doc, currentURL)
+	doc.Find("link[href]").Each(func(i int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		if linkType, ok := linkRelationType(rel); !ok || linkType != Canonical {
+			return
+		}
+
+		href, _ := s.Attr("href")
+		href = strings.TrimSpace(href)
+		link := extractResourceLink(href)
+		if link == nil {
+			return
+		}
+
+		link.TargetURL = *baseURL.ResolveReference(&link.TargetURL)
+		canonicals = append(canonicals, CanonicalLink{Href: href, URL: link.pageURL()})
+	})
+
+	return
+}
+
+// crawlCanonicals crawls the canonical URLs of the pages passed which were not
+// crawled yet, so that their status and own canonical links can be checked.
+// Canonical URLs of the sitemap hosts point to the host crawled if it is
+// overridden. Canonical URLs of other hosts than the ones of the sitemap URLs
+// passed are only crawled if external links are.
+func crawlCanonicals(urls []string, results map[string]*HTTPResponse, config CrawlConfig,
+	quit <-chan struct{}) (map[string]*HTTPResponse, CrawlStats, time.Duration) {
+	if config.Host != "" {
+		rewriteCanonicalHosts(results, config.Host, config.sitemapHosts)
+	}
+
+	crawled := normalizedResults(results)
+	hosts := urlHosts(urls)
+	var canonicalUrls []string
+	found := make(map[string]bool)
+	for _, result := range results {
+		for _, canonical := range result.Canonicals {
+			if _, ok := crawled[normalizeURL(canonical.URL)]; ok || found[canonical.URL] ||
+				!config.Links.allowsScheme(schemeOf(canonical.URL)) ||
+				(!config.Links.CrawlExternalLinks && !hosts[hostOf(canonical.URL)]) {
+				continue
+			}
+			found[canonical.URL] = true
+			canonicalUrls = append(canonicalUrls, canonical.URL)
+		}
+	}
+
+	canonicalConfig := config
+	canonicalConfig.HTTP.ParseLinks = false
+	canonicalConfig.HTTP.Fingerprint = false
+	canonicalConfig.notFoundTemplates = nil
+
+	log.Info("Found ", len(canonicalUrls), " canonical URL(s) to check")
+	canonicalResults, stats, server200TimeSum := crawlUrls(canonicalUrls, canonicalConfig, quit)
+	if config.Host != "" {
+		rewriteCanonicalHosts(canonicalResults, config.Host, config.sitemapHosts)
+	}
+
+	return canonicalResults, stats, server200TimeSum
+}
+
+// rewriteCanonicalHosts points the canonical URLs of the pages passed whose
+// host is one of the sitemap hosts to a new host, the same way as sitemap
+// URLs, so that canonical URLs of the production site match the pages crawled
+func rewriteCanonicalHosts(results map[string]*HTTPResponse, newHost string, sitemapHosts map[string]bool) {
+	for _, result := range results {
+		for i, canonical := range result.Canonicals {
+			if !sitemapHosts[hostOf(canonical.URL)] {
+				continue
+			}
+			if rewritten := RewriteURLHost([]string{canonical.URL}, newHost); len(rewritten) == 1 {
+				result.Canonicals[i].URL = rewritten[0]
+			}
+		}
+	}
+}
+
+func schemeOf(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Scheme
+}
+
+func hostOf(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Host
+}
+
+// urlHosts returns the set of hosts of the URLs passed
+func urlHosts(urls []string) map[string]bool {
+	hosts := make(map[string]bool)
+	for _, rawURL := range urls {
+		hosts[hostOf(rawURL)] = true
+	}
+	return hosts
+}
+
+// normalizedResults returns the results passed keyed by normalized URL, see
+// normalizeURL
+func normalizedResults(results map[string]*HTTPResponse) map[string]*HTTPResponse {
+	normalized := make(map[string]*HTTPResponse, len(results))
+	for rawURL, result := range results {
+		normalized[normalizeURL(rawURL)] = result
+	}
+	return normalized
+}
+
+// canonicalIssues checks the canonical links of the crawled pages passed:
+// canonical URLs must be absolute, return a 200 status without redirection,
+// and not have a canonical URL of their own. Sitemap pages must be their own
+// canonical URL. URLs are compared once normalized, see normalizeURL.
+func canonicalIssues(urls []string, results map[string]*HTTPResponse) (issues []CrawlResult) {
+	sitemap := make(map[string]bool)
+	for _, rawURL := range urls {
+		sitemap[normalizeURL(rawURL)] = true
+	}
+
+	crawled := normalizedResults(results)
+	for pageURL, result := range results {
+		if len(result.Canonicals) == 0 {
+			continue
+		}
+
+		pageIssues := checkCanonical(result, crawled, sitemap[normalizeURL(pageURL)])
+		if len(pageIssues) > 0 {
+			issues = append(issues, CrawlResult{
+				URL:        result.URL,
+				StatusCode: result.StatusCode,
+				Issues:     pageIssues,
+			})
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].URL < issues[j].URL
+	})
+
+	return
+}
+
+func checkCanonical(result *HTTPResponse, crawled map[string]*HTTPResponse, inSitemap bool) (issues []string) {
+	var distinct []string
+	for _, canonical := range result.Canonicals {
+		distinct = appendUnique(distinct, normalizeURL(canonical.URL))
+	}
+	if len(distinct) > 1 {
+		issues = append(issues, "multiple canonical URLs: "+strings.Join(distinct, ", "))
+	}
+
+	// Only the first canonical link is checked, others being conflicting
+	canonical := result.Canonicals[0]
+	if href, err := url.Parse(canonical.Href); err == nil && !href.IsAbs() {
+		issues = append(issues, fmt.Sprintf("canonical URL '%s' is not absolute", canonical.Href))
+	}
+
+	canonicalURL := normalizeURL(canonical.URL)
+	if inSitemap && canonicalURL != normalizeURL(result.URL) {
+		issues = append(issues, "canonical URL points to "+canonical.URL)
+	}
+
+	target, ok := crawled[canonicalURL]
+	if !ok || target == result {
+		return
+	}
+
+	if target.StatusCode != http.StatusOK {
+		return append(issues, fmt.Sprintf("canonical URL %s returns status %d", canonical.URL, target.StatusCode))
+	}
+	if target.Response != nil && spiderURL(*target.Response.Request.URL) != canonicalURL {
+		return append(issues, "canonical URL "+canonical.URL+" redirects to "+target.Response.Request.URL.String())
+	}
+	if len(target.Canonicals) > 0 && normalizeURL(target.Canonicals[0].URL) != canonicalURL {
+		issues = append(issues, "canonical URL "+canonical.URL+" chains to "+target.Canonicals[0].URL)
+	}
+
+	return
+}
@@ -0,0 +1,130 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsyncCrawlCheckCanonicals(t *testing.T) {
+	var server *httptest.Server
+	canonical := func(href string) string {
+		return `<link rel="canonical" href="` + strings.Replace(href, "SERVER", server.URL, 1) + `">`
+	}
+	pages := map[string]func() string{
+		"/":         func() string { return canonical("SERVER/") },
+		"/relative": func() string { return canonical("/relative") },
+		"/variant":  func() string { return canonical("SERVER/") },
+		"/gone":     func() string { return canonical("SERVER/deleted") },
+		"/moved":    func() string { return canonical("SERVER/old") },
+		"/chained":  func() string { return canonical("SERVER/first") },
+		"/first":    func() string { return canonical("SERVER/second") },
+		"/second":   func() string { return canonical("SERVER/second") },
+		"/multiple": func() string { return canonical("SERVER/multiple") + canonical("SERVER/") },
+		"/external": func() string { return canonical("https://www.example.com/") },
+	}
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/second", http.StatusMovedPermanently)
+			return
+		}
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><head>", page(), "</head></html>")
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second, CheckCanonicals: true},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+	}
+	urls := []string{server.URL, server.URL + "/relative", server.URL + "/variant", server.URL + "/gone",
+		server.URL + "/moved", server.URL + "/chained", server.URL + "/multiple", server.URL + "/external"}
+
+	stats, err := AsyncCrawl(urls, config, make(chan struct{}))
+	if err == nil {
+		t.Errorf("expected canonical issues to be returned as an error")
+	}
+
+	expected := map[string][]string{
+		"/relative": {"canonical URL '/relative' is not absolute"},
+		"/variant":  {"canonical URL points to SERVER/"},
+		"/gone": {"canonical URL points to SERVER/deleted",
+			"canonical URL SERVER/deleted returns status 404"},
+		"/moved": {"canonical URL points to SERVER/old",
+			"canonical URL SERVER/old redirects to SERVER/second"},
+		"/chained": {"canonical URL points to SERVER/first",
+			"canonical URL SERVER/first chains to SERVER/second"},
+		"/multiple": {"multiple canonical URLs: SERVER/multiple, SERVER/"},
+		"/external": {"canonical URL points to https://www.example.com/"},
+	}
+	if len(stats.CanonicalIssues) != len(expected) {
+		t.Errorf("expected %d pages with canonical issues, got %+v", len(expected), stats.CanonicalIssues)
+	}
+	for _, result := range stats.CanonicalIssues {
+		issues := expected[strings.TrimPrefix(result.URL, server.URL)]
+		if len(issues) != len(result.Issues) {
+			t.Errorf("unexpected issues for %s: %v", result.URL, result.Issues)
+			continue
+		}
+		for i, issue := range issues {
+			if strings.ReplaceAll(issue, "SERVER", server.URL) != result.Issues[i] {
+				t.Errorf("expected issue '%s' for %s, got '%s'", issue, result.URL, result.Issues[i])
+			}
+		}
+	}
+
+	// Canonical URLs of canonical URLs, and external ones, are not crawled
+	if stats.Total != len(urls)+3 {
+		t.Errorf("expected canonical URLs to be crawled once, got %d pages", stats.Total)
+	}
+
+	if merged := MergeCrawlStats(stats, stats); len(merged.CanonicalIssues) != len(expected) {
+		t.Errorf("expected canonical issues to be listed once across iterations, got %+v",
+			merged.CanonicalIssues)
+	}
+}
+
+func TestAsyncCrawlCheckCanonicalsHostOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		canonical := "http://www.foo.bar/"
+		if r.URL.Path == "/partner" {
+			canonical = "http://www.partner.bar/"
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><link rel="canonical" href="`+canonical+`"></head></html>`)
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		Host:       strings.TrimPrefix(server.URL, "http://"),
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second, CheckCanonicals: true},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+	}
+	urls := []string{"http://www.foo.bar/", "http://www.foo.bar/variant", "http://www.foo.bar/partner"}
+
+	stats, _ := AsyncCrawl(urls, config, make(chan struct{}))
+
+	if len(stats.CanonicalIssues) != 2 || stats.CanonicalIssues[1].URL != server.URL+"/variant" ||
+		len(stats.CanonicalIssues[1].Issues) != 1 ||
+		stats.CanonicalIssues[1].Issues[0] != "canonical URL points to "+server.URL+"/" {
+		t.Errorf("expected canonical URLs to point to the host crawled, got %+v", stats.CanonicalIssues)
+	}
+	if stats.CanonicalIssues[0].URL != server.URL+"/partner" ||
+		stats.CanonicalIssues[0].Issues[0] != "canonical URL points to http://www.partner.bar/" {
+		t.Errorf("expected canonical URLs of other hosts to be kept, got %+v", stats.CanonicalIssues)
+	}
+	if stats.Total != len(urls) {
+		t.Errorf("expected production canonical URLs not to be crawled, got %d pages", stats.Total)
+	}
+}
@@ -0,0 +1,211 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// CertificateInfo holds health information on the certificate chain served
+// by a host
+type CertificateInfo struct {
+	Host            string    `json:"host"`
+	Subject         string    `json:"subject"`
+	Issuer          string    `json:"issuer"`
+	NotAfter        time.Time `json:"not-after"`
+	DaysLeft        int       `json:"days-left"`
+	HostnameCovered bool      `json:"hostname-covered"`
+	WeakSignatures  []string  `json:"weak-signatures,omitempty"`
+	IncompleteChain bool      `json:"incomplete-chain"`
+	Error           string    `json:"error,omitempty"`
+	Issues          []string  `json:"issues,omitempty"`
+}
+
+var weakSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.MD2WithRSA:    true,
+	x509.MD5WithRSA:    true,
+	x509.SHA1WithRSA:   true,
+	x509.DSAWithSHA1:   true,
+	x509.ECDSAWithSHA1: true,
+}
+
+func hostname(host string) string {
+	name, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+	return name
+}
+
+func certificateName(name pkix.Name) string {
+	if name.CommonName != "" {
+		return name.CommonName
+	}
+	return name.String()
+}
+
+func isSelfSigned(certificate *x509.Certificate) bool {
+	return certificate.CheckSignatureFrom(certificate) == nil
+}
+
+// InspectCertificates returns health information on the certificate chain
+// of the TLS connection state provided. Certificates expiring within warnDays
+// days are reported as an issue.
+func InspectCertificates(host string, state *tls.ConnectionState, warnDays int) CertificateInfo {
+	info := inspectChain(host, state.PeerCertificates)
+
+	// The transport may complete chains using intermediates it already knows,
+	// which most clients would not do
+	if len(state.VerifiedChains) > 0 {
+		sent := make(map[string]bool)
+		for _, certificate := range state.PeerCertificates {
+			sent[string(certificate.Raw)] = true
+		}
+
+		chain := state.VerifiedChains[0]
+		for _, certificate := range chain[:len(chain)-1] {
+			if !sent[string(certificate.Raw)] {
+				info.IncompleteChain = true
+			}
+		}
+	}
+
+	info.Issues = info.issues(warnDays)
+	return info
+}
+
+// inspectCertificateError returns health information on the certificate
+// chain of a host whose certificate verification failed, or nil if err is
+// not a certificate verification error
+func inspectCertificateError(host string, err error, warnDays int) *CertificateInfo {
+	var verificationErr *tls.CertificateVerificationError
+	if !errors.As(err, &verificationErr) {
+		return nil
+	}
+
+	info := inspectChain(host, verificationErr.UnverifiedCertificates)
+	info.Error = verificationErr.Err.Error()
+
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	certificates := verificationErr.UnverifiedCertificates
+	if errors.As(verificationErr.Err, &unknownAuthorityErr) && len(certificates) > 0 &&
+		!isSelfSigned(certificates[len(certificates)-1]) {
+		info.IncompleteChain = true
+	}
+
+	info.Issues = info.issues(warnDays)
+	return &info
+}
+
+func inspectChain(host string, certificates []*x509.Certificate) (info CertificateInfo) {
+	info.Host = host
+	if len(certificates) == 0 {
+		return
+	}
+
+	leaf := certificates[0]
+	info.Subject = certificateName(leaf.Subject)
+	info.Issuer = certificateName(leaf.Issuer)
+	info.HostnameCovered = leaf.VerifyHostname(hostname(host)) == nil
+
+	info.NotAfter = leaf.NotAfter
+	for _, certificate := range certificates {
+		if certificate.NotAfter.Before(info.NotAfter) {
+			info.NotAfter = certificate.NotAfter
+		}
+
+		if weakSignatureAlgorithms[certificate.SignatureAlgorithm] && !isSelfSigned(certificate) {
+			info.WeakSignatures = append(info.WeakSignatures, fmt.Sprintf("%s (%s)",
+				certificateName(certificate.Subject), certificate.SignatureAlgorithm))
+		}
+	}
+	info.DaysLeft = int(time.Until(info.NotAfter).Hours() / 24)
+
+	return
+}
+
+func (info CertificateInfo) issues(warnDays int) (issues []string) {
+	if info.Error != "" {
+		issues = append(issues, "verification failed: "+info.Error)
+	}
+	if info.NotAfter.IsZero() {
+		return
+	}
+
+	if info.DaysLeft < 0 {
+		issues = append(issues, "certificate chain has expired")
+	} else if info.DaysLeft < warnDays {
+		issues = append(issues, fmt.Sprintf("certificate chain expires in %d days", info.DaysLeft))
+	}
+	if !info.HostnameCovered {
+		issues = append(issues, "certificate does not cover the hostname")
+	}
+	for _, weakSignature := range info.WeakSignatures {
+		issues = append(issues, "weak signature algorithm: "+weakSignature)
+	}
+	if info.IncompleteChain {
+		issues = append(issues, "incomplete certificate chain")
+	}
+
+	return
+}
+
+func collectCertificates(result *HTTPResponse, config CrawlConfig, stats *CrawlStats) {
+	var info *CertificateInfo
+	if result.Response != nil && result.Response.TLS != nil {
+		host := result.Response.Request.URL.Host
+		if _, ok := stats.Certificates[host]; ok {
+			return
+		}
+		inspected := InspectCertificates(host, result.Response.TLS, config.CertificateWarnDays)
+		info = &inspected
+	} else if result.Err != nil {
+		parsedURL, err := url.Parse(result.URL)
+		if err != nil {
+			return
+		}
+		if _, ok := stats.Certificates[parsedURL.Host]; ok {
+			return
+		}
+		info = inspectCertificateError(parsedURL.Host, result.Err, config.CertificateWarnDays)
+	}
+
+	if info == nil {
+		return
+	}
+	if stats.Certificates == nil {
+		stats.Certificates = make(map[string]CertificateInfo)
+	}
+	stats.Certificates[info.Host] = *info
+}
+
+// sortedCertificates returns the certificates information sorted by host
+func sortedCertificates(certificates map[string]CertificateInfo) []CertificateInfo {
+	sorted := make([]CertificateInfo, 0, len(certificates))
+	for _, info := range certificates {
+		sorted = append(sorted, info)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Host < sorted[j].Host
+	})
+
+	return sorted
+}
+
+// CertificateIssues returns the number of hosts whose certificates have
+// issues
+func (stats CrawlStats) CertificateIssues() (count int) {
+	for _, info := range stats.Certificates {
+		if len(info.Issues) > 0 {
+			count++
+		}
+	}
+
+	return
+}
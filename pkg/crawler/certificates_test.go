@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCertificatesReport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	tests := []struct {
+		name            string
+		transport       http.RoundTripper
+		warnDays        int
+		expectedIssues  int
+		expectedError   bool
+		expectedCovered bool
+	}{
+		{
+			name:            "Trusted certificate",
+			transport:       server.Client().Transport,
+			warnDays:        14,
+			expectedIssues:  0,
+			expectedCovered: true,
+		},
+		{
+			name:            "Certificate expiring before threshold",
+			transport:       server.Client().Transport,
+			warnDays:        1000000,
+			expectedIssues:  1,
+			expectedCovered: true,
+		},
+		{
+			name:            "Untrusted certificate",
+			transport:       NewTransport(TransportConfig{}),
+			warnDays:        14,
+			expectedIssues:  1,
+			expectedError:   true,
+			expectedCovered: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := CrawlConfig{
+				Throttle:            1,
+				HTTP:                HTTPConfig{Timeout: 5 * time.Second, Transport: tt.transport},
+				HTTPGetter:          &BaseConcurrentHTTPGetter{Get: HTTPGet},
+				CheckCertificates:   true,
+				CertificateWarnDays: tt.warnDays,
+			}
+
+			stats, _ := AsyncCrawl([]string{server.URL + "/a", server.URL + "/b"}, config, make(chan struct{}))
+			info, ok := stats.Certificates[serverURL.Host]
+			if !ok || len(stats.Certificates) != 1 {
+				t.Fatalf("expected a single certificate report for %s, got %v", serverURL.Host, stats.Certificates)
+			}
+
+			if len(info.Issues) != tt.expectedIssues {
+				t.Errorf("expected %d issues, got %v", tt.expectedIssues, info.Issues)
+			}
+			if (info.Error != "") != tt.expectedError {
+				t.Errorf("expected error: %v, got: '%s'", tt.expectedError, info.Error)
+			}
+			if info.HostnameCovered != tt.expectedCovered {
+				t.Errorf("expected hostname covered: %v, got: %v", tt.expectedCovered, info.HostnameCovered)
+			}
+			if info.IncompleteChain {
+				t.Errorf("expected chain not to be reported as incomplete")
+			}
+			if info.DaysLeft <= 0 || info.Issuer == "" {
+				t.Errorf("expected certificate details to be filled, got %+v", info)
+			}
+		})
+	}
+}
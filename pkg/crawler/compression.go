@@ -0,0 +1,135 @@
+package crawler
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultAcceptEncoding is the Accept-Encoding header sent when reporting
+// compression, unless set otherwise. Only encodings crowlet can decode are
+// advertised, which are those of most browsers.
+const DefaultAcceptEncoding = "gzip, deflate, br"
+
+// CompressionInfo holds the encoding and sizes of a response body
+type CompressionInfo struct {
+	ContentType  string `json:"content-type"`
+	Encoding     string `json:"encoding,omitempty"`
+	TransferSize int64  `json:"transfer-size"`
+	BodySize     int64  `json:"body-size"`
+}
+
+// CompressionStats holds the transfer statistics of a content type
+type CompressionStats struct {
+	Responses    int   `json:"responses"`
+	Compressed   int   `json:"compressed"`
+	TransferSize int64 `json:"transfer-size"`
+	BodySize     int64 `json:"body-size"`
+}
+
+func newCompressionInfo(header http.Header, transferSize int64) *CompressionInfo {
+	contentType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		contentType = "unknown"
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	if encoding == "identity" {
+		encoding = ""
+	}
+
+	return &CompressionInfo{
+		ContentType:  contentType,
+		Encoding:     encoding,
+		TransferSize: transferSize,
+	}
+}
+
+// isCompressible returns whether the media type passed is text based, and
+// expected to be served compressed
+func isCompressible(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+xml") ||
+		strings.HasSuffix(mediaType, "+json") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/javascript", "application/x-javascript",
+		"application/xml", "application/manifest+json", "image/svg+xml":
+		return true
+	}
+
+	return false
+}
+
+// acceptsCompression returns whether an Accept-Encoding header advertises
+// gzip or brotli
+func acceptsCompression(acceptEncoding string) bool {
+	for _, value := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(value, ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		if coding != "gzip" && coding != "br" {
+			continue
+		}
+
+		accepted := true
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				quality, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				accepted = err == nil && quality > 0
+			}
+		}
+		if accepted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compressionIssues returns why a response should have been compressed, if
+// it is a text response of at least minSize bytes served uncompressed
+// although the client accepts compression
+func (info CompressionInfo) compressionIssues(acceptEncoding string, minSize int64) []string {
+	if minSize <= 0 || info.Encoding != "" || info.BodySize < minSize ||
+		!isCompressible(info.ContentType) || !acceptsCompression(acceptEncoding) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s response of %d bytes is not compressed", info.ContentType, info.BodySize)}
+}
+
+// add counts the response described. The sizes of responses that could not
+// be decoded are not counted, as their decompressed size is unknown.
+func (stats *CompressionStats) add(info CompressionInfo) {
+	stats.Responses++
+	if info.Encoding != "" {
+		stats.Compressed++
+	}
+
+	if info.BodySize > 0 || info.TransferSize == 0 {
+		stats.TransferSize += info.TransferSize
+		stats.BodySize += info.BodySize
+	}
+}
+
+func (stats CompressionStats) merge(other CompressionStats) CompressionStats {
+	return CompressionStats{
+		Responses:    stats.Responses + other.Responses,
+		Compressed:   stats.Compressed + other.Compressed,
+		TransferSize: stats.TransferSize + other.TransferSize,
+		BodySize:     stats.BodySize + other.BodySize,
+	}
+}
+
+// Ratio returns the size transferred relative to the decompressed size, from
+// 0 to 1. Lower is better.
+func (stats CompressionStats) Ratio() float64 {
+	if stats.BodySize == 0 {
+		return 1
+	}
+	return float64(stats.TransferSize) / float64(stats.BodySize)
+}
@@ -0,0 +1,117 @@
+package crawler
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestAcceptsCompression(t *testing.T) {
+	tests := map[string]bool{
+		"":                   false,
+		"identity":           false,
+		"gzip":               true,
+		"deflate, br":        true,
+		"gzip;q=0, deflate":  false,
+		"GZIP;q=0.5":         true,
+		"compress, br;q=0.0": false,
+	}
+
+	for acceptEncoding, expected := range tests {
+		if result := acceptsCompression(acceptEncoding); result != expected {
+			t.Errorf("expected '%s' to accept compression: %t, got %t", acceptEncoding, expected, result)
+		}
+	}
+}
+
+func TestIsCompressible(t *testing.T) {
+	tests := map[string]bool{
+		"text/html":                true,
+		"application/json":         true,
+		"application/ld+json":      true,
+		"image/svg+xml":            true,
+		"image/png":                false,
+		"application/octet-stream": false,
+	}
+
+	for mediaType, expected := range tests {
+		if result := isCompressible(mediaType); result != expected {
+			t.Errorf("expected '%s' to be compressible: %t, got %t", mediaType, expected, result)
+		}
+	}
+}
+
+func TestAsyncCrawlCompressionReport(t *testing.T) {
+	content := strings.Repeat("<p>Lorem ipsum dolor sit amet</p>", 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/compressed":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Encoding", "gzip")
+			writer := gzip.NewWriter(w)
+			writer.Write([]byte(content))
+			writer.Close()
+		case "/brotli":
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), "br") {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Encoding", "br")
+			writer := brotli.NewWriter(w)
+			writer.Write([]byte(content))
+			writer.Close()
+		case "/plain":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(content))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte(content))
+		}
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle: 2,
+		HTTP: HTTPConfig{
+			Timeout:            5 * time.Second,
+			CompressionReport:  true,
+			CompressionMinSize: 1024,
+		},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+	}
+
+	urls := []string{server.URL + "/compressed", server.URL + "/brotli", server.URL + "/plain", server.URL + "/image"}
+	stats, err := AsyncCrawl(urls, config, make(chan struct{}))
+	if err == nil {
+		t.Errorf("expected an error to be reported")
+	}
+
+	if len(stats.CompressionIssues) != 1 || stats.CompressionIssues[0].URL != server.URL+"/plain" {
+		t.Errorf("expected only the plain page to be reported, got %v", stats.CompressionIssues)
+	}
+
+	html := stats.Compression["text/html"]
+	if html.Responses != 3 || html.Compressed != 2 {
+		t.Errorf("unexpected HTML compression stats %+v", html)
+	}
+	if html.BodySize != int64(3*len(content)) || html.TransferSize >= html.BodySize {
+		t.Errorf("unexpected HTML sizes %+v", html)
+	}
+	if stats.TotalBodySize != html.TransferSize+int64(len(content)) {
+		t.Errorf("expected transferred body sizes to be counted, got %d", stats.TotalBodySize)
+	}
+	if image := stats.Compression["image/png"]; image.Responses != 1 || image.Ratio() != 1 {
+		t.Errorf("unexpected image compression stats %+v", image)
+	}
+
+	if merged := MergeCrawlStats(stats, stats); len(merged.CompressionIssues) != 1 {
+		t.Errorf("expected compression issues to be listed once across iterations, got %v",
+			merged.CompressionIssues)
+	}
+}
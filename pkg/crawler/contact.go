@@ -0,0 +1,116 @@
+package crawler
+
+import (
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// checkMailtoLink returns the syntax issues of a 'mailto' link, such as
+// "mailto:sales@foo.bar?subject=Hello". Recipients may be listed in the
+// address part as well as in 'to' query parameters.
+func checkMailtoLink(link url.URL) (issues []string) {
+	var recipients []string
+	if address := link.Opaque; address != "" {
+		if unescaped, err := url.PathUnescape(address); err == nil {
+			address = unescaped
+		}
+		recipients = strings.Split(address, ",")
+	}
+
+	query, err := url.ParseQuery(link.RawQuery)
+	if err != nil {
+		issues = append(issues, "invalid query: "+err.Error())
+	}
+	for _, to := range query["to"] {
+		recipients = append(recipients, strings.Split(to, ",")...)
+	}
+
+	if len(recipients) == 0 {
+		return append(issues, "no recipient")
+	}
+
+	for _, recipient := range recipients {
+		recipient = strings.TrimSpace(recipient)
+		address, err := mail.ParseAddress(recipient)
+		if err != nil || address.Address != recipient {
+			issues = append(issues, "invalid email address '"+recipient+"'")
+		}
+	}
+
+	return
+}
+
+// checkTelLink returns the syntax issues of a 'tel' link, such as
+// "tel:+1-201-555-0123". Numbers hold digits and visual separators, and
+// global numbers start with '+'.
+func checkTelLink(link url.URL) (issues []string) {
+	number := link.Opaque
+	if unescaped, err := url.PathUnescape(number); err == nil {
+		number = unescaped
+	}
+	// Parameters, such as the extension or phone context, follow the number
+	if separator := strings.Index(number, ";"); separator >= 0 {
+		number = number[:separator]
+	}
+
+	digits := 0
+	for i, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case strings.ContainsRune("-.() ", r):
+		case strings.ContainsRune("*#", r) && !strings.HasPrefix(number, "+"):
+		default:
+			return []string{"invalid character '" + string(r) + "' in phone number '" + number + "'"}
+		}
+	}
+
+	if digits == 0 {
+		return []string{"no phone number"}
+	}
+
+	return nil
+}
+
+// contactLinkIssues returns the 'mailto' and 'tel' hyperlinks of the crawled
+// pages which are not valid, along with the pages linking to them
+func contactLinkIssues(results map[string]*HTTPResponse) (invalid []CrawlResult) {
+	linkingURLs := make(map[string][]string)
+	reports := make(map[string]CrawlResult)
+	for _, result := range results {
+		for _, link := range result.Links {
+			if link.Type != Hyperlink {
+				continue
+			}
+
+			var issues []string
+			switch strings.ToLower(link.TargetURL.Scheme) {
+			case "mailto":
+				issues = checkMailtoLink(link.TargetURL)
+			case "tel":
+				issues = checkTelLink(link.TargetURL)
+			}
+			if len(issues) == 0 {
+				continue
+			}
+
+			linkURL := link.TargetURL.String()
+			linkingURLs[linkURL] = appendUnique(linkingURLs[linkURL], result.URL)
+			reports[linkURL] = CrawlResult{URL: linkURL, Issues: issues}
+		}
+	}
+
+	for linkURL, report := range reports {
+		report.LinkingURLs = linkingURLs[linkURL]
+		sort.Strings(report.LinkingURLs)
+		invalid = append(invalid, report)
+	}
+	sort.Slice(invalid, func(i, j int) bool {
+		return invalid[i].URL < invalid[j].URL
+	})
+
+	return
+}
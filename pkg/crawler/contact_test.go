@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCheckContactLinks(t *testing.T) {
+	tests := map[string]bool{
+		"mailto:sales@foo.bar":                   true,
+		"mailto:sales@foo.bar,support@foo.bar":   true,
+		"mailto:?to=sales@foo.bar&subject=Hello": true,
+		"mailto:sales%40foo.bar":                 true,
+		"mailto:":                                false,
+		"mailto:sales":                           false,
+		"mailto:sales@foo.bar,":                  false,
+		"mailto:Sales <sales@foo.bar>":           false,
+		"tel:+1-201-555-0123":                    true,
+		"tel:(201)%20555.0123":                   true,
+		"tel:7042;phone-context=example.com":     true,
+		"tel:*31%23":                             true,
+		"tel:":                                   false,
+		"tel:+1-CALL-NOW":                        false,
+		"tel:+*31":                               false,
+	}
+
+	for rawURL, valid := range tests {
+		link, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var issues []string
+		if link.Scheme == "mailto" {
+			issues = checkMailtoLink(*link)
+		} else {
+			issues = checkTelLink(*link)
+		}
+		if valid != (len(issues) == 0) {
+			t.Errorf("expected validity of '%s' to be %t, got issues %v", rawURL, valid, issues)
+		}
+	}
+}
+
+func TestContactLinkIssues(t *testing.T) {
+	invalid, _ := url.Parse("mailto:sales")
+	valid, _ := url.Parse("tel:+33123456789")
+	page, _ := url.Parse("https://foo.bar/page")
+
+	results := map[string]*HTTPResponse{
+		"https://foo.bar/": {URL: "https://foo.bar/", Links: []Link{
+			{Type: Hyperlink, TargetURL: *invalid}, {Type: Hyperlink, TargetURL: *valid},
+			{Type: Hyperlink, TargetURL: *page},
+		}},
+		"https://foo.bar/contact": {URL: "https://foo.bar/contact", Links: []Link{
+			{Type: Hyperlink, TargetURL: *invalid}, {Type: Hyperlink, TargetURL: *invalid},
+		}},
+	}
+
+	issues := contactLinkIssues(results)
+	if len(issues) != 1 || issues[0].URL != "mailto:sales" || len(issues[0].LinkingURLs) != 2 ||
+		issues[0].LinkingURLs[0] != "https://foo.bar/" {
+		t.Errorf("expected invalid email link to be reported once, got %+v", issues)
+	}
+
+	stats := CrawlStats{ContactLinkIssues: issues}
+	if merged := MergeCrawlStats(stats, stats); len(merged.ContactLinkIssues) != 1 {
+		t.Errorf("expected contact link issues to be listed once across iterations, got %+v",
+			merged.ContactLinkIssues)
+	}
+}
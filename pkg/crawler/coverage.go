@@ -0,0 +1,94 @@
+package crawler
+
+import (
+	"net/url"
+	"sort"
+)
+
+// inboundLinks maps the URL of internal pages to the URLs of the crawled
+// pages linking to them
+type inboundLinks map[string][]string
+
+// buildInboundLinks returns the hyperlinks between the crawled pages passed
+// and pages of the origins given, keyed by normalized URL. Fragments are
+// ignored, as well as links from a page to itself.
+func buildInboundLinks(results map[string]*HTTPResponse, origins map[string]bool) inboundLinks {
+	graph := make(inboundLinks)
+	for _, result := range results {
+		for _, link := range result.Links {
+			if link.Type != Hyperlink {
+				continue
+			}
+
+			target := link.TargetURL
+			if !origins[target.Scheme+"://"+target.Host] {
+				continue
+			}
+
+			targetURL := spiderURL(target)
+			if targetURL != normalizeURL(result.URL) {
+				graph[targetURL] = appendUnique(graph[targetURL], result.URL)
+			}
+		}
+	}
+
+	for _, sources := range graph {
+		sort.Strings(sources)
+	}
+
+	return graph
+}
+
+// sitemapCoverage compares the sitemap URLs with the link graph of the
+// crawled pages, and returns the sitemap URLs no crawled page links to, as
+// well as the internal pages linked to which are not in the sitemap
+func sitemapCoverage(urls []string, results map[string]*HTTPResponse) (orphans []CrawlResult,
+	notInSitemap []CrawlResult) {
+	sitemap := make(map[string]bool)
+	origins := make(map[string]bool)
+	for _, rawURL := range urls {
+		sitemap[normalizeURL(rawURL)] = true
+		origins[origin(rawURL)] = true
+	}
+
+	graph := buildInboundLinks(results, origins)
+
+	for _, rawURL := range urls {
+		if _, linked := graph[normalizeURL(rawURL)]; linked {
+			continue
+		}
+
+		orphan := CrawlResult{URL: rawURL}
+		if result, ok := results[rawURL]; ok {
+			orphan.StatusCode = result.StatusCode
+		}
+		orphans = append(orphans, orphan)
+	}
+
+	for targetURL, sources := range graph {
+		if sitemap[targetURL] {
+			continue
+		}
+
+		missing := CrawlResult{URL: targetURL, LinkingURLs: sources}
+		if result, ok := results[targetURL]; ok {
+			missing.StatusCode = result.StatusCode
+		}
+		notInSitemap = append(notInSitemap, missing)
+	}
+	sort.Slice(notInSitemap, func(i, j int) bool {
+		return notInSitemap[i].URL < notInSitemap[j].URL
+	})
+
+	return
+}
+
+// normalizeURL returns the URL passed normalized as spidered URLs are, or
+// unchanged if it can not be parsed
+func normalizeURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return spiderURL(*parsedURL)
+}
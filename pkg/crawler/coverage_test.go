@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAsyncCrawlCoverage(t *testing.T) {
+	pages := map[string]string{
+		"/":         `<a href="/products">Products</a><a href="/about#team">About</a><a href="/">Home</a>`,
+		"/products": `<a href="/products/blue">Blue</a><a href="/deleted">Deleted</a><a href="https://example.com/">Out</a>`,
+		"/about":    `<a href="/products">Products</a><a href="/contact">Contact</a>`,
+		"/legal":    `<a href="/">Home</a><img src="/logo.png">`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>", page, "</body></html>")
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		Coverage:   true,
+	}
+	urls := []string{server.URL + "/", server.URL + "/products", server.URL + "/about", server.URL + "/legal"}
+
+	stats, _ := AsyncCrawl(urls, config, make(chan struct{}))
+
+	if len(stats.Orphans) != 1 || stats.Orphans[0].URL != server.URL+"/legal" ||
+		stats.Orphans[0].StatusCode != 200 {
+		t.Errorf("expected only legal page to be orphan, got %+v", stats.Orphans)
+	}
+
+	expected := []struct {
+		path    string
+		linking []string
+	}{
+		{"/contact", []string{"/about"}},
+		{"/deleted", []string{"/products"}},
+		{"/products/blue", []string{"/products"}},
+	}
+	if len(stats.NotInSitemap) != len(expected) {
+		t.Fatalf("expected %d pages missing from sitemap, got %+v", len(expected), stats.NotInSitemap)
+	}
+	for i, missing := range stats.NotInSitemap {
+		if missing.URL != server.URL+expected[i].path || len(missing.LinkingURLs) != len(expected[i].linking) ||
+			missing.LinkingURLs[0] != server.URL+expected[i].linking[0] {
+			t.Errorf("unexpected missing page report %+v", missing)
+		}
+	}
+
+	merged := MergeCrawlStats(stats, stats)
+	if len(merged.Orphans) != 1 || len(merged.NotInSitemap) != len(expected) {
+		t.Errorf("expected coverage reports to be listed once across iterations, got %+v and %+v",
+			merged.Orphans, merged.NotInSitemap)
+	}
+
+	config.Spider.MaxDepth = 1
+	stats, _ = AsyncCrawl(urls, config, make(chan struct{}))
+	if len(stats.NotInSitemap) != len(expected) || stats.NotInSitemap[1].StatusCode != http.StatusNotFound {
+		t.Errorf("expected status of discovered pages to be reported, got %+v", stats.NotInSitemap)
+	}
+}
+
+func TestSitemapCoverageNormalizesURLs(t *testing.T) {
+	home, _ := url.Parse("https://foo.bar/")
+	about, _ := url.Parse("https://foo.bar/about#team")
+	results := map[string]*HTTPResponse{
+		"https://foo.bar": {URL: "https://foo.bar", StatusCode: 200,
+			Links: []Link{{Type: Hyperlink, TargetURL: *home}, {Type: Hyperlink, TargetURL: *about}}},
+		"https://foo.bar/about": {URL: "https://foo.bar/about", StatusCode: 200,
+			Links: []Link{{Type: Hyperlink, TargetURL: *home}}},
+	}
+
+	orphans, notInSitemap := sitemapCoverage([]string{"https://foo.bar", "https://foo.bar/about"}, results)
+	if len(orphans) != 0 || len(notInSitemap) != 0 {
+		t.Errorf("expected URLs differing by their trailing slash to match, got %+v and %+v",
+			orphans, notInSitemap)
+	}
+}
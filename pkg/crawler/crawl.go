@@ -2,7 +2,9 @@ package crawler
 
 import (
 	"errors"
+	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -15,6 +17,7 @@ type CrawlResult struct {
 	StatusCode  int           `json:"status-code"`
 	Time        time.Duration `json:"server-time"`
 	LinkingURLs []string      `json:"linking-urls"`
+	Issues      []string      `json:"issues,omitempty"`
 }
 
 // CrawlStats holds crawling related information: status codes, time
@@ -25,6 +28,57 @@ type CrawlStats struct {
 	Average200Time time.Duration
 	Max200Time     time.Duration
 	Non200Urls     []CrawlResult
+	TotalBodySize  int64
+	// Discovered is the number of pages found by following links
+	// recursively, which are not in the sitemap
+	Discovered int
+
+	// ResponseTimes holds the distribution of 200 response times
+	ResponseTimes Histogram
+	// PhaseTimes holds the distribution of each timing phase of requests
+	PhaseTimes map[string]Histogram
+
+	AssertionFailures []CrawlResult
+	Soft404Urls       []CrawlResult
+
+	ExpectedStatuses   int
+	UnexpectedStatuses []CrawlResult
+
+	Certificates map[string]CertificateInfo
+
+	SecurityIssues []CrawlResult
+
+	RevalidationIssues []CrawlResult
+	MixedContent       []CrawlResult
+
+	Compression       map[string]CompressionStats
+	CompressionIssues []CrawlResult
+
+	Cache           CacheStats
+	CacheIterations []CacheStats
+	UncacheableUrls []CrawlResult
+	// CacheHeaders holds the caching headers of the last response of each URL
+	CacheHeaders map[string]CacheInfo
+
+	Variants map[string]VariantStats
+
+	Groups map[string]GroupStats
+
+	BrokenAnchors     []CrawlResult
+	ContactLinkIssues []CrawlResult
+	CanonicalIssues   []CrawlResult
+
+	// SEOIssues holds the sitemap pages failing the SEO audit, by issue type
+	SEOIssues map[string][]CrawlResult
+
+	Orphans      []CrawlResult
+	NotInSitemap []CrawlResult
+
+	Graph *LinkGraph
+
+	// PolicyResults holds the outcome of policy rules, as set after crawling
+	// using EvaluatePolicy
+	PolicyResults []RuleResult
 }
 
 // CrawlConfig holds crawling configuration.
@@ -33,7 +87,40 @@ type CrawlConfig struct {
 	Host       string
 	HTTP       HTTPConfig
 	Links      CrawlPageLinksConfig
+	Spider     SpiderConfig
 	HTTPGetter ConcurrentHTTPGetter
+	Transport  TransportConfig
+
+	DetectSoft404    bool
+	Soft404Threshold float64
+	Status           StatusPolicy
+	Variants         []Variant
+	Groups           GroupPolicy
+	Coverage         bool
+	CheckAnchors     bool
+
+	CheckContactLinks bool
+	Graph             bool
+	SEO               SEOConfig
+
+	CheckCertificates   bool
+	CertificateWarnDays int
+
+	notFoundTemplates map[string]*notFoundTemplate
+	// sitemapHosts holds the hosts of the sitemap URLs before their host is
+	// overridden
+	sitemapHosts map[string]bool
+	// notFoundProbes holds the not-found templates per variant name, kept
+	// across successive crawls so that hosts are probed once
+	notFoundProbes map[string]map[string]*notFoundTemplate
+}
+
+// Crawler crawls URLs using a single HTTP transport, shared by all workers
+// and kept across successive crawls so that connections can be reused.
+type Crawler struct {
+	Config         CrawlConfig
+	transport      *http.Transport
+	notFoundProbes map[string]map[string]*notFoundTemplate
 }
 
 // CrawlPageLinksConfig holds the crawling policy for links
@@ -41,12 +128,120 @@ type CrawlPageLinksConfig struct {
 	CrawlExternalLinks bool
 	CrawlHyperlinks    bool
 	CrawlImages        bool
+	CrawlStylesheets   bool
+	CrawlIcons         bool
+	CrawlPreloads      bool
+	CrawlCanonicals    bool
+	CrawlAlternates    bool
+	CrawlScripts       bool
+	CrawlFrames        bool
+	CrawlMedia         bool
+	CrawlObjects       bool
+	CrawlForms         bool
+	CrawlRefreshes     bool
+
+	// Schemes lists the URL schemes of links which can be crawled.
+	// DefaultLinkSchemes apply if empty.
+	Schemes         []string
+	RespectNoFollow bool
+}
+
+// DefaultLinkSchemes lists the URL schemes of links crawled by default
+var DefaultLinkSchemes = []string{"http", "https"}
+
+// allowsScheme returns whether links with the URL scheme passed can be crawled
+func (config CrawlPageLinksConfig) allowsScheme(scheme string) bool {
+	schemes := config.Schemes
+	if len(schemes) == 0 {
+		schemes = DefaultLinkSchemes
+	}
+
+	for _, allowed := range schemes {
+		if strings.EqualFold(allowed, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+func (config CrawlPageLinksConfig) isEnabled() bool {
+	return config.CrawlExternalLinks || config.CrawlHyperlinks || config.CrawlImages ||
+		config.CrawlStylesheets || config.CrawlIcons || config.CrawlPreloads || config.CrawlCanonicals ||
+		config.CrawlAlternates || config.CrawlScripts || config.CrawlFrames || config.CrawlMedia ||
+		config.CrawlObjects || config.CrawlForms || config.CrawlRefreshes
+}
+
+// crawlsType returns whether links of the type passed are crawled
+func (config CrawlPageLinksConfig) crawlsType(linkType LinkType) bool {
+	switch linkType {
+	case Hyperlink:
+		return config.CrawlHyperlinks
+	case Image:
+		return config.CrawlImages
+	case Stylesheet:
+		return config.CrawlStylesheets
+	case Icon:
+		return config.CrawlIcons
+	case Preload:
+		return config.CrawlPreloads
+	case Canonical:
+		return config.CrawlCanonicals
+	case Alternate:
+		return config.CrawlAlternates
+	case Script:
+		return config.CrawlScripts
+	case Frame:
+		return config.CrawlFrames
+	case Media:
+		return config.CrawlMedia
+	case Object:
+		return config.CrawlObjects
+	case Form:
+		return config.CrawlForms
+	case Refresh:
+		return config.CrawlRefreshes
+	}
+
+	return false
+}
+
+// NewCrawler creates a Crawler and its HTTP transport from the configuration
+// passed. Unless set, the number of idle connections kept per host matches
+// the throttle.
+func NewCrawler(config CrawlConfig) *Crawler {
+	if config.Transport.MaxIdleConnsPerHost <= 0 {
+		config.Transport.MaxIdleConnsPerHost = config.Throttle
+	}
+
+	return &Crawler{
+		Config:         config,
+		transport:      NewTransport(config.Transport),
+		notFoundProbes: make(map[string]map[string]*notFoundTemplate),
+	}
+}
+
+// Crawl crawls the URLs passed using the crawler's shared transport. See
+// AsyncCrawl for details.
+func (crawler *Crawler) Crawl(urls []string, quit <-chan struct{}) (stats CrawlStats, err error) {
+	config := crawler.Config
+	config.HTTP.Transport = crawler.transport
+	config.notFoundProbes = crawler.notFoundProbes
+	return AsyncCrawl(urls, config, quit)
+}
+
+// CloseIdleConnections closes the connections kept alive by the crawler,
+// forcing the next requests to establish new ones.
+func (crawler *Crawler) CloseIdleConnections() {
+	crawler.transport.CloseIdleConnections()
 }
 
 // MergeCrawlStats merges two sets of crawling statistics together.
 func MergeCrawlStats(statsA, statsB CrawlStats) (stats CrawlStats) {
 	stats.StatusCodes = make(map[int]int)
 	stats.Total = statsA.Total + statsB.Total
+	stats.ExpectedStatuses = statsA.ExpectedStatuses + statsB.ExpectedStatuses
+	stats.TotalBodySize = statsA.TotalBodySize + statsB.TotalBodySize
+	stats.Discovered = statsA.Discovered + statsB.Discovered
 
 	if statsA.Max200Time > statsB.Max200Time {
 		stats.Max200Time = statsA.Max200Time
@@ -65,6 +260,19 @@ func MergeCrawlStats(statsA, statsB CrawlStats) (stats CrawlStats) {
 		}
 	}
 
+	stats.ResponseTimes = statsA.ResponseTimes.Merge(statsB.ResponseTimes)
+	stats.PhaseTimes = mergePhaseTimes(statsA.PhaseTimes, statsB.PhaseTimes)
+
+	if statsA.Groups != nil || statsB.Groups != nil {
+		stats.Groups = make(map[string]GroupStats)
+		for name, groupStats := range statsA.Groups {
+			stats.Groups[name] = groupStats
+		}
+		for name, groupStats := range statsB.Groups {
+			stats.Groups[name] = stats.Groups[name].merge(groupStats)
+		}
+	}
+
 	if statsA.Average200Time != 0 || statsB.Average200Time != 0 {
 		total200ns := (statsA.Average200Time.Nanoseconds()*int64(statsA.StatusCodes[200]) +
 			statsB.Average200Time.Nanoseconds()*int64(statsB.StatusCodes[200]))
@@ -74,6 +282,96 @@ func MergeCrawlStats(statsA, statsB CrawlStats) (stats CrawlStats) {
 	stats.Non200Urls = append(stats.Non200Urls, statsA.Non200Urls...)
 	stats.Non200Urls = append(stats.Non200Urls, statsB.Non200Urls...)
 
+	stats.AssertionFailures = appendUniqueResults(stats.AssertionFailures, statsA.AssertionFailures)
+	stats.AssertionFailures = appendUniqueResults(stats.AssertionFailures, statsB.AssertionFailures)
+
+	stats.Soft404Urls = appendUniqueResults(stats.Soft404Urls, statsA.Soft404Urls)
+	stats.Soft404Urls = appendUniqueResults(stats.Soft404Urls, statsB.Soft404Urls)
+
+	stats.UnexpectedStatuses = appendUniqueResults(stats.UnexpectedStatuses, statsA.UnexpectedStatuses)
+	stats.UnexpectedStatuses = appendUniqueResults(stats.UnexpectedStatuses, statsB.UnexpectedStatuses)
+
+	stats.Cache = statsA.Cache.merge(statsB.Cache)
+	stats.CacheIterations = append(stats.CacheIterations, statsA.CacheIterations...)
+	stats.CacheIterations = append(stats.CacheIterations, statsB.CacheIterations...)
+	stats.UncacheableUrls = appendUniqueResults(stats.UncacheableUrls, statsA.UncacheableUrls)
+	stats.UncacheableUrls = appendUniqueResults(stats.UncacheableUrls, statsB.UncacheableUrls)
+	if statsA.CacheHeaders != nil || statsB.CacheHeaders != nil {
+		stats.CacheHeaders = make(map[string]CacheInfo)
+		for url, info := range statsA.CacheHeaders {
+			stats.CacheHeaders[url] = info
+		}
+		for url, info := range statsB.CacheHeaders {
+			stats.CacheHeaders[url] = info
+		}
+	}
+
+	if statsA.Variants != nil || statsB.Variants != nil {
+		stats.Variants = make(map[string]VariantStats)
+		for name, variantStats := range statsA.Variants {
+			stats.Variants[name] = variantStats
+		}
+		for name, variantStats := range statsB.Variants {
+			if existing, ok := stats.Variants[name]; ok {
+				variantStats = mergeVariantStats(existing, variantStats)
+			}
+			stats.Variants[name] = variantStats
+		}
+	}
+
+	stats.SecurityIssues = appendUniqueResults(stats.SecurityIssues, statsA.SecurityIssues)
+	stats.SecurityIssues = appendUniqueResults(stats.SecurityIssues, statsB.SecurityIssues)
+
+	stats.RevalidationIssues = appendUniqueResults(stats.RevalidationIssues, statsA.RevalidationIssues)
+	stats.RevalidationIssues = appendUniqueResults(stats.RevalidationIssues, statsB.RevalidationIssues)
+	stats.MixedContent = appendUniqueResults(stats.MixedContent, statsA.MixedContent)
+	stats.MixedContent = appendUniqueResults(stats.MixedContent, statsB.MixedContent)
+
+	stats.BrokenAnchors = appendUniqueResults(stats.BrokenAnchors, statsA.BrokenAnchors)
+	stats.BrokenAnchors = appendUniqueResults(stats.BrokenAnchors, statsB.BrokenAnchors)
+	stats.ContactLinkIssues = appendUniqueResults(stats.ContactLinkIssues, statsA.ContactLinkIssues)
+	stats.ContactLinkIssues = appendUniqueResults(stats.ContactLinkIssues, statsB.ContactLinkIssues)
+	stats.CanonicalIssues = appendUniqueResults(stats.CanonicalIssues, statsA.CanonicalIssues)
+	stats.CanonicalIssues = appendUniqueResults(stats.CanonicalIssues, statsB.CanonicalIssues)
+
+	if statsA.SEOIssues != nil || statsB.SEOIssues != nil {
+		stats.SEOIssues = make(map[string][]CrawlResult)
+		for _, source := range []CrawlStats{statsA, statsB} {
+			for issueType, results := range source.SEOIssues {
+				stats.SEOIssues[issueType] = appendUniqueResults(stats.SEOIssues[issueType], results)
+			}
+		}
+	}
+
+	stats.Graph = mergeLinkGraphs(statsA.Graph, statsB.Graph)
+
+	stats.Orphans = appendUniqueResults(stats.Orphans, statsA.Orphans)
+	stats.Orphans = appendUniqueResults(stats.Orphans, statsB.Orphans)
+	stats.NotInSitemap = appendUniqueResults(stats.NotInSitemap, statsA.NotInSitemap)
+	stats.NotInSitemap = appendUniqueResults(stats.NotInSitemap, statsB.NotInSitemap)
+
+	if statsA.Compression != nil || statsB.Compression != nil {
+		stats.Compression = make(map[string]CompressionStats)
+		for contentType, compressionStats := range statsA.Compression {
+			stats.Compression[contentType] = compressionStats
+		}
+		for contentType, compressionStats := range statsB.Compression {
+			stats.Compression[contentType] = stats.Compression[contentType].merge(compressionStats)
+		}
+	}
+	stats.CompressionIssues = appendUniqueResults(stats.CompressionIssues, statsA.CompressionIssues)
+	stats.CompressionIssues = appendUniqueResults(stats.CompressionIssues, statsB.CompressionIssues)
+
+	if statsA.Certificates != nil || statsB.Certificates != nil {
+		stats.Certificates = make(map[string]CertificateInfo)
+		for host, info := range statsA.Certificates {
+			stats.Certificates[host] = info
+		}
+		for host, info := range statsB.Certificates {
+			stats.Certificates[host] = info
+		}
+	}
+
 	return
 }
 
@@ -116,55 +414,191 @@ func GetSitemapUrlsAsStrings(sitemapURL string) (urls []string, err error) {
 // AsyncCrawl crawls asynchronously URLs from a sitemap and prints related
 // information. Throttle is the maximum number of parallel HTTP requests.
 // Host overrides the hostname used in the sitemap if provided,
-// and user/pass are optional basic auth credentials
+// and user/pass are optional basic auth credentials. If variants are
+// configured, URLs are crawled once per variant.
 func AsyncCrawl(urls []string, config CrawlConfig, quit <-chan struct{}) (stats CrawlStats, err error) {
+	if err = config.HTTP.Validate(); err != nil {
+		return
+	}
 	if config.Throttle <= 0 {
 		log.Warn("Invalid throttle value, defaulting to 1.")
 		config.Throttle = 1
 	}
 	if config.Host != "" {
+		config.sitemapHosts = urlHosts(urls)
 		urls = RewriteURLHost(urls, config.Host)
 	}
 
-	config.HTTP.ParseLinks = config.Links.CrawlExternalLinks || config.Links.CrawlHyperlinks ||
-		config.Links.CrawlImages
-	results, stats, server200TimeSum := crawlUrls(urls, config, quit)
+	config.HTTP.ParseLinks = config.Links.isEnabled() || config.Coverage || config.CheckAnchors ||
+		config.CheckContactLinks || config.HTTP.CheckMixedContent || config.Graph
+	config.HTTP.ParseAnchors = config.CheckAnchors
+	if config.DetectSoft404 {
+		config.HTTP.Fingerprint = true
+	}
 
-	if config.HTTP.ParseLinks {
-		_, pageLinksStats, linksServer200TimeSum := crawlPageLinks(results, config, quit)
-		stats = MergeCrawlStats(stats, pageLinksStats)
-		server200TimeSum += linksServer200TimeSum
+	if len(config.Variants) == 0 {
+		stats = crawlSitemapUrls(urls, withNotFoundTemplates(urls, config, "", quit), quit)
+	} else {
+		stats.Variants = make(map[string]VariantStats)
+		for _, variant := range config.Variants {
+			log.Info("Crawling variant ", variant.Name)
+			variantConfig := config
+			variantConfig.HTTP.Headers = config.HTTP.Headers.Clone()
+			if variantConfig.HTTP.Headers == nil {
+				variantConfig.HTTP.Headers = http.Header{}
+			}
+			for name, values := range variant.Header {
+				variantConfig.HTTP.Headers[name] = values
+			}
+
+			variantConfig = withNotFoundTemplates(urls, variantConfig, variant.Name, quit)
+			variantStats := crawlSitemapUrls(urls, variantConfig, quit)
+			stats = MergeCrawlStats(stats, variantStats)
+			stats.Variants[variant.Name] = newVariantStats(variantStats)
+		}
 	}
 
-	total200 := stats.StatusCodes[200]
-	if total200 > 0 {
-		stats.Average200Time = server200TimeSum / time.Duration(total200)
+	if config.HTTP.CacheReport {
+		stats.CacheIterations = []CacheStats{stats.Cache}
 	}
 
 	if stats.Total == 0 {
 		err = errors.New("no URL crawled")
-	} else if stats.Total != stats.StatusCodes[200] {
-		err = errors.New("some URLs had a different status code than 200")
+	} else if len(stats.UnexpectedStatuses) > 0 {
+		err = errors.New("some URLs had an unexpected status code")
+	} else if len(stats.AssertionFailures) > 0 {
+		err = errors.New("some URLs failed content assertions")
+	} else if len(stats.Soft404Urls) > 0 {
+		err = errors.New("some URLs look like not-found pages")
+	} else if stats.CertificateIssues() > 0 {
+		err = errors.New("some hosts have certificate issues")
+	} else if len(stats.SecurityIssues) > 0 {
+		err = errors.New("some pages have security header issues")
+	} else if len(stats.RevalidationIssues) > 0 {
+		err = errors.New("some pages do not support revalidation")
+	} else if len(stats.MixedContent) > 0 {
+		err = errors.New("some https pages load http resources")
+	} else if len(stats.CompressionIssues) > 0 {
+		err = errors.New("some text responses are not compressed")
+	} else if len(stats.BrokenAnchors) > 0 {
+		err = errors.New("some links target missing anchors")
+	} else if len(stats.ContactLinkIssues) > 0 {
+		err = errors.New("some contact links are invalid")
+	} else if len(stats.CanonicalIssues) > 0 {
+		err = errors.New("some pages have canonical link issues")
+	} else if stats.SEOIssueCount() > 0 {
+		err = errors.New("some sitemap pages have SEO issues")
 	}
 
 	return
 }
 
+// withNotFoundTemplates returns the configuration passed with the not-found
+// templates of the hosts of the URLs passed if soft-404 pages are detected.
+// Hosts are probed with the headers of the variant, once per crawler.
+func withNotFoundTemplates(urls []string, config CrawlConfig, variant string, quit <-chan struct{}) CrawlConfig {
+	if !config.DetectSoft404 {
+		return config
+	}
+
+	templates, ok := config.notFoundProbes[variant]
+	if !ok {
+		templates = make(map[string]*notFoundTemplate)
+		if config.notFoundProbes != nil {
+			config.notFoundProbes[variant] = templates
+		}
+	}
+
+	probeNotFoundTemplates(urls, config, templates, quit)
+	config.notFoundTemplates = templates
+	return config
+}
+
+// crawlSitemapUrls crawls the sitemap URLs passed and the links they
+// contain, as configured
+func crawlSitemapUrls(urls []string, config CrawlConfig, quit <-chan struct{}) CrawlStats {
+	var results map[string]*HTTPResponse
+	var stats CrawlStats
+	var server200TimeSum time.Duration
+	if config.Spider.IsEnabled() {
+		results, stats, server200TimeSum = crawlSpider(urls, config, quit)
+	} else {
+		results, stats, server200TimeSum = crawlUrls(urls, config, quit)
+	}
+
+	if config.Coverage {
+		stats.Orphans, stats.NotInSitemap = sitemapCoverage(urls, results)
+	}
+
+	if config.CheckContactLinks {
+		stats.ContactLinkIssues = contactLinkIssues(results)
+	}
+
+	var linksResults map[string]*HTTPResponse
+	if config.Links.isEnabled() {
+		var pageLinksStats CrawlStats
+		var linksServer200TimeSum time.Duration
+		linksResults, pageLinksStats, linksServer200TimeSum = crawlPageLinks(results, config, quit)
+		stats = MergeCrawlStats(stats, pageLinksStats)
+		server200TimeSum += linksServer200TimeSum
+	}
+
+	if config.CheckAnchors || config.Graph || config.HTTP.CheckCanonicals {
+		// Links to linked pages are checked and exported too
+		for url, result := range linksResults {
+			if _, ok := results[url]; !ok {
+				results[url] = result
+			}
+		}
+	}
+
+	if config.CheckAnchors {
+		stats.BrokenAnchors = brokenAnchors(results)
+	}
+
+	if config.HTTP.CheckCanonicals {
+		canonicalResults, canonicalStats, canonicalServer200TimeSum := crawlCanonicals(urls, results, config, quit)
+		stats = MergeCrawlStats(stats, canonicalStats)
+		server200TimeSum += canonicalServer200TimeSum
+		for url, result := range canonicalResults {
+			results[url] = result
+		}
+		stats.CanonicalIssues = canonicalIssues(urls, results)
+	}
+
+	if config.HTTP.AuditSEO {
+		stats.SEOIssues = seoAudit(urls, results, config.SEO)
+	}
+
+	if config.Graph {
+		stats.Graph = newLinkGraph(results)
+	}
+
+	total200 := stats.StatusCodes[200]
+	if total200 > 0 {
+		stats.Average200Time = server200TimeSum / time.Duration(total200)
+	}
+
+	return stats
+}
+
 func crawlPageLinks(sourceResults map[string]*HTTPResponse, sourceConfig CrawlConfig, quit <-chan struct{}) (map[string]*HTTPResponse,
 	CrawlStats, time.Duration) {
 	linkedUrlsSet := make(map[string][]string)
 	for _, result := range sourceResults {
 		for _, link := range result.Links {
 			if (!sourceConfig.Links.CrawlExternalLinks && link.IsExternal) ||
-				(!sourceConfig.Links.CrawlHyperlinks && link.Type == Hyperlink) ||
-				(!sourceConfig.Links.CrawlImages && link.Type == Image) {
+				!sourceConfig.Links.crawlsType(link.Type) ||
+				!sourceConfig.Links.allowsScheme(link.TargetURL.Scheme) ||
+				(sourceConfig.Links.RespectNoFollow && link.NoFollow) {
 				continue
 			}
 			// Skip if already present in sourceResults
-			if _, ok := sourceResults[link.TargetURL.String()]; ok {
+			targetURL := link.pageURL()
+			if _, ok := sourceResults[targetURL]; ok {
 				continue
 			}
-			linkedUrlsSet[link.TargetURL.String()] = append(linkedUrlsSet[link.TargetURL.String()], result.URL)
+			linkedUrlsSet[targetURL] = appendUnique(linkedUrlsSet[targetURL], result.URL)
 		}
 	}
 
@@ -176,22 +610,101 @@ func crawlPageLinks(sourceResults map[string]*HTTPResponse, sourceConfig CrawlCo
 	// Make exploration non-recursive by not collecting any more links.
 	linksConfig := sourceConfig
 	linksConfig.HTTP.ParseLinks = false
-	linksConfig.Links = CrawlPageLinksConfig{
-		CrawlExternalLinks: false,
-		CrawlImages:        false,
-		CrawlHyperlinks:    false}
+	linksConfig.HTTP.Fingerprint = false
+	linksConfig.notFoundTemplates = nil
+	linksConfig.Links = CrawlPageLinksConfig{}
+	linksConfig.HTTP.ParseStylesheets = sourceConfig.Links.CrawlStylesheets
 
 	log.Info("Found ", len(linkedUrls), " relevant linked URL(s)")
 	linksResults, linksStats, linksServer200TimeSum := crawlUrls(linkedUrls, linksConfig, quit)
 
-	for i, linkResult := range linksStats.Non200Urls {
-		linkResult.LinkingURLs = linkedUrlsSet[linkResult.URL]
-		linksStats.Non200Urls[i] = linkResult
+	// Resources of stylesheets, including imported stylesheets, are crawled
+	// until none is left
+	stylesheetResults := linksResults
+	for len(stylesheetResults) > 0 {
+		resourceUrls := stylesheetResources(stylesheetResults, sourceResults, linkedUrlsSet,
+			sourceConfig.Links)
+		if len(resourceUrls) == 0 {
+			break
+		}
+
+		log.Info("Found ", len(resourceUrls), " stylesheet resource(s)")
+		var resourceStats CrawlStats
+		var resourceServer200TimeSum time.Duration
+		stylesheetResults, resourceStats, resourceServer200TimeSum = crawlUrls(resourceUrls, linksConfig, quit)
+		for url, result := range stylesheetResults {
+			linksResults[url] = result
+		}
+		linksStats = MergeCrawlStats(linksStats, resourceStats)
+		linksServer200TimeSum += resourceServer200TimeSum
 	}
 
+	setStatsLinkingURLs(linksStats, linkedUrlsSet)
+
 	return linksResults, linksStats, linksServer200TimeSum
 }
 
+// stylesheetResources returns the URLs referenced by the stylesheets passed
+// which were not crawled yet, and records the stylesheets as linking to them
+func stylesheetResources(stylesheets map[string]*HTTPResponse, sourceResults map[string]*HTTPResponse,
+	linkedUrlsSet map[string][]string, config CrawlPageLinksConfig) (resourceUrls []string) {
+	for _, stylesheet := range stylesheets {
+		for _, link := range stylesheet.Links {
+			if (!config.CrawlExternalLinks && link.IsExternal) || !config.allowsScheme(link.TargetURL.Scheme) {
+				continue
+			}
+
+			targetURL := link.pageURL()
+			if _, ok := sourceResults[targetURL]; ok {
+				continue
+			}
+			if _, ok := linkedUrlsSet[targetURL]; !ok {
+				resourceUrls = append(resourceUrls, targetURL)
+			}
+			linkedUrlsSet[targetURL] = appendUnique(linkedUrlsSet[targetURL], stylesheet.URL)
+		}
+	}
+
+	return
+}
+
+// appendUniqueResults appends the results passed whose URL is not listed yet,
+// for reports describing the site rather than a crawling iteration
+func appendUniqueResults(crawlResults []CrawlResult, others []CrawlResult) []CrawlResult {
+	listed := make(map[string]bool)
+	for _, crawlResult := range crawlResults {
+		listed[crawlResult.URL] = true
+	}
+
+	for _, crawlResult := range others {
+		if !listed[crawlResult.URL] {
+			listed[crawlResult.URL] = true
+			crawlResults = append(crawlResults, crawlResult)
+		}
+	}
+
+	return crawlResults
+}
+
+func setLinkingURLs(crawlResults []CrawlResult, linkedUrlsSet map[string][]string) {
+	for i := range crawlResults {
+		crawlResults[i].LinkingURLs = linkedUrlsSet[crawlResults[i].URL]
+	}
+}
+
+// setStatsLinkingURLs sets the pages linking to each URL reported by the
+// crawling stats passed
+func setStatsLinkingURLs(stats CrawlStats, linkedUrlsSet map[string][]string) {
+	for _, crawlResults := range [][]CrawlResult{stats.Non200Urls, stats.UnexpectedStatuses,
+		stats.AssertionFailures, stats.Soft404Urls, stats.SecurityIssues, stats.RevalidationIssues,
+		stats.MixedContent, stats.CompressionIssues, stats.UncacheableUrls} {
+		setLinkingURLs(crawlResults, linkedUrlsSet)
+	}
+	for _, groupStats := range stats.Groups {
+		setLinkingURLs(groupStats.Unexpected, linkedUrlsSet)
+	}
+}
+
 func crawlUrls(urls []string, config CrawlConfig, quit <-chan struct{}) (results map[string]*HTTPResponse,
 	stats CrawlStats, server200TimeSum time.Duration) {
 
@@ -199,14 +712,23 @@ func crawlUrls(urls []string, config CrawlConfig, quit <-chan struct{}) (results
 	stats.StatusCodes = make(map[int]int)
 	resultsChan := config.HTTPGetter.ConcurrentHTTPGet(urls, config.HTTP, config.Throttle, quit)
 	for result := range resultsChan {
-		populateCrawlStats(result, &stats, &server200TimeSum)
+		populateCrawlStats(result, config, &stats, &server200TimeSum)
+		if isSoft404, similarity := detectSoft404(result, config.notFoundTemplates,
+			config.Soft404Threshold); isSoft404 {
+			stats.Soft404Urls = append(stats.Soft404Urls, CrawlResult{
+				URL:        result.URL,
+				StatusCode: result.StatusCode,
+				Issues:     []string{soft404Issue(similarity)},
+			})
+		}
 		results[result.URL] = result
 	}
 	return
 }
 
-func populateCrawlStats(result *HTTPResponse, stats *CrawlStats, total200Time *time.Duration) {
+func populateCrawlStats(result *HTTPResponse, config CrawlConfig, stats *CrawlStats, total200Time *time.Duration) {
 	stats.Total++
+	stats.TotalBodySize += result.BodySize
 
 	statusCode := result.StatusCode
 	serverTime := time.Duration(0)
@@ -216,8 +738,18 @@ func populateCrawlStats(result *HTTPResponse, stats *CrawlStats, total200Time *t
 
 	stats.StatusCodes[statusCode]++
 
+	for phase, phaseTime := range phaseTimes(result) {
+		if stats.PhaseTimes == nil {
+			stats.PhaseTimes = make(map[string]Histogram)
+		}
+		histogram := stats.PhaseTimes[phase]
+		histogram.Record(phaseTime)
+		stats.PhaseTimes[phase] = histogram
+	}
+
 	if statusCode == 200 {
 		*total200Time += serverTime
+		stats.ResponseTimes.Record(serverTime)
 
 		if serverTime > stats.Max200Time {
 			stats.Max200Time = serverTime
@@ -229,4 +761,114 @@ func populateCrawlStats(result *HTTPResponse, stats *CrawlStats, total200Time *t
 			StatusCode: statusCode,
 		})
 	}
+
+	expected := config.Status.IsExpected(result.URL, statusCode)
+	if expected {
+		stats.ExpectedStatuses++
+	} else {
+		stats.UnexpectedStatuses = append(stats.UnexpectedStatuses, CrawlResult{
+			URL:        result.URL,
+			Time:       serverTime,
+			StatusCode: statusCode,
+		})
+	}
+
+	if config.Groups.IsEnabled() {
+		if stats.Groups == nil {
+			stats.Groups = make(map[string]GroupStats)
+		}
+
+		name := config.Groups.GroupOf(result.URL)
+		groupStats := stats.Groups[name]
+		if groupStats.StatusCodes == nil {
+			groupStats.StatusCodes = make(map[int]int)
+		}
+		groupStats.Total++
+		groupStats.StatusCodes[statusCode]++
+		if statusCode == 200 {
+			groupStats.ResponseTimes.Record(serverTime)
+		}
+		if !expected {
+			groupStats.Unexpected = append(groupStats.Unexpected, CrawlResult{
+				URL:        result.URL,
+				Time:       serverTime,
+				StatusCode: statusCode,
+			})
+		}
+		stats.Groups[name] = groupStats
+	}
+
+	if config.CheckCertificates {
+		collectCertificates(result, config, stats)
+	}
+
+	if result.Cache != nil {
+		stats.Cache.add(result.Cache.Result)
+		if stats.CacheHeaders == nil {
+			stats.CacheHeaders = make(map[string]CacheInfo)
+		}
+		stats.CacheHeaders[result.URL] = *result.Cache
+		if reasons := result.Cache.UncacheableReasons(); statusCode == 200 && len(reasons) > 0 {
+			stats.UncacheableUrls = append(stats.UncacheableUrls, CrawlResult{
+				URL:        result.URL,
+				Time:       serverTime,
+				StatusCode: statusCode,
+				Issues:     reasons,
+			})
+		}
+	}
+
+	if len(result.SecurityIssues) > 0 {
+		stats.SecurityIssues = append(stats.SecurityIssues, CrawlResult{
+			URL:        result.URL,
+			Time:       serverTime,
+			StatusCode: statusCode,
+			Issues:     result.SecurityIssues,
+		})
+	}
+
+	if len(result.RevalidationIssues) > 0 {
+		stats.RevalidationIssues = append(stats.RevalidationIssues, CrawlResult{
+			URL:        result.URL,
+			Time:       serverTime,
+			StatusCode: statusCode,
+			Issues:     result.RevalidationIssues,
+		})
+	}
+
+	if len(result.MixedContent) > 0 {
+		stats.MixedContent = append(stats.MixedContent, CrawlResult{
+			URL:        result.URL,
+			Time:       serverTime,
+			StatusCode: statusCode,
+			Issues:     result.MixedContent,
+		})
+	}
+
+	if result.Compression != nil {
+		if stats.Compression == nil {
+			stats.Compression = make(map[string]CompressionStats)
+		}
+		compressionStats := stats.Compression[result.Compression.ContentType]
+		compressionStats.add(*result.Compression)
+		stats.Compression[result.Compression.ContentType] = compressionStats
+	}
+
+	if len(result.CompressionIssues) > 0 {
+		stats.CompressionIssues = append(stats.CompressionIssues, CrawlResult{
+			URL:        result.URL,
+			Time:       serverTime,
+			StatusCode: statusCode,
+			Issues:     result.CompressionIssues,
+		})
+	}
+
+	if len(result.AssertionFailures) > 0 {
+		stats.AssertionFailures = append(stats.AssertionFailures, CrawlResult{
+			URL:        result.URL,
+			Time:       serverTime,
+			StatusCode: statusCode,
+			Issues:     result.AssertionFailures,
+		})
+	}
 }
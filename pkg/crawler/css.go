@@ -0,0 +1,62 @@
+package crawler
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// cssCommentPattern matches CSS comments, which may hold disabled rules
+var cssCommentPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// cssReferencePattern matches '@import' rules, whose URL is held by the
+// first three groups, and 'url()' references, whose URL is held by the
+// last three groups. Quoted and unquoted forms are supported.
+var cssReferencePattern = regexp.MustCompile(
+	`(?i)@import\s*(?:url\(\s*)?(?:"([^"]*)"|'([^']*)'|([^\s)'";]+))` +
+		`|url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s'"]*))\s*\)`)
+
+func isCSS(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == "text/css"
+}
+
+// ExtractStylesheetLinks returns the '@import' rules and 'url()' references
+// of the stylesheet provided, resolved against the stylesheet URL. Imports
+// are Stylesheet links, and other references StylesheetResource links.
+func ExtractStylesheetLinks(content []byte, stylesheetURL url.URL) (links []Link) {
+	css := cssCommentPattern.ReplaceAll(content, nil)
+
+	for _, match := range cssReferencePattern.FindAllSubmatch(css, -1) {
+		linkType := Stylesheet
+		var targetURL string
+		for group := 1; group <= 6; group++ {
+			if match[group] != nil {
+				targetURL = string(match[group])
+				if group > 3 {
+					linkType = StylesheetResource
+				}
+				break
+			}
+		}
+
+		// Fragments only reference elements of the document, such as SVG
+		// filters
+		if strings.HasPrefix(strings.TrimSpace(targetURL), "#") {
+			continue
+		}
+
+		link := extractResourceLink(targetURL)
+		if link == nil {
+			continue
+		}
+
+		link.Type = linkType
+		links = append(links, *link)
+	}
+
+	resolveLinks(links, stylesheetURL, stylesheetURL)
+	return
+}
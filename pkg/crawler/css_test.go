@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExtractStylesheetLinks(t *testing.T) {
+	css := `@import "theme.css";
+		@import url('print.css') print;
+		/* .old { background: url(/old.png) } */
+		body { background: url(../img/bg.png) no-repeat; }
+		.icon { mask: url( "icons.svg#star" ); filter: url(#blur); }
+		@font-face { src: url(data:font/woff2;base64,AAAA), URL('https://cdn.foo.bar/font.woff2'); }`
+	stylesheetURL, _ := url.Parse("https://foo.bar/css/main.css")
+
+	links := ExtractStylesheetLinks([]byte(css), *stylesheetURL)
+
+	found := make(map[string]LinkType)
+	for _, link := range links {
+		found[link.TargetURL.String()] = link.Type
+	}
+	expected := map[string]LinkType{
+		"https://foo.bar/css/theme.css":      Stylesheet,
+		"https://foo.bar/css/print.css":      Stylesheet,
+		"https://foo.bar/img/bg.png":         StylesheetResource,
+		"https://foo.bar/css/icons.svg#star": StylesheetResource,
+		"https://cdn.foo.bar/font.woff2":     StylesheetResource,
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected links %v, got %v", expected, found)
+	}
+	for _, link := range links {
+		if link.IsExternal != (link.TargetURL.Host == "cdn.foo.bar") {
+			t.Errorf("unexpected external flag for %s", link.TargetURL.String())
+		}
+	}
+}
+
+func TestAsyncCrawlStylesheetResources(t *testing.T) {
+	var mutex sync.Mutex
+	requests := make(map[string]int)
+	files := map[string]string{
+		"/":               `<html><head><link rel="stylesheet" href="/css/main.css"></head></html>`,
+		"/css/main.css":   `@import "theme.css"; body { background: url(/img/bg.png) }`,
+		"/css/theme.css":  `h1 { background: url(/img/bg.png) } h2 { background: url(../img/gone.png) }`,
+		"/img/bg.png":     "",
+		"/img/unused.png": "",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		mutex.Unlock()
+
+		file, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path == "/" {
+			w.Header().Set("Content-Type", "text/html")
+		} else if r.URL.Path != "/img/bg.png" {
+			w.Header().Set("Content-Type", "text/css")
+		}
+		fmt.Fprint(w, file)
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		Links:      CrawlPageLinksConfig{CrawlStylesheets: true},
+	}
+
+	stats, _ := AsyncCrawl([]string{server.URL + "/"}, config, make(chan struct{}))
+
+	if stats.Total != 5 {
+		t.Errorf("expected page, stylesheets and resources to be crawled, got %d", stats.Total)
+	}
+	for path, count := range requests {
+		if count != 1 {
+			t.Errorf("expected %s to be crawled once, got %d", path, count)
+		}
+	}
+
+	if len(stats.UnexpectedStatuses) != 1 {
+		t.Fatalf("expected only missing image to be reported, got %v", stats.UnexpectedStatuses)
+	}
+	missing := stats.UnexpectedStatuses[0]
+	if missing.URL != server.URL+"/img/gone.png" || len(missing.LinkingURLs) != 1 ||
+		missing.LinkingURLs[0] != server.URL+"/css/theme.css" {
+		t.Errorf("unexpected missing image report %+v", missing)
+	}
+}
@@ -0,0 +1,339 @@
+package crawler
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Link graph export formats
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatGraphML = "graphml"
+	GraphFormatJSON    = "json"
+)
+
+// GraphEdge is a link from a crawled page to a target URL. The status is the
+// one of the target, or 0 if it was not crawled.
+type GraphEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Type     string `json:"type"`
+	Status   int    `json:"status,omitempty"`
+	External bool   `json:"external"`
+}
+
+// GraphNode is a URL of the link graph with its number of distinct linking
+// pages and linked URLs
+type GraphNode struct {
+	URL       string `json:"url"`
+	Status    int    `json:"status,omitempty"`
+	InDegree  int    `json:"in-degree"`
+	OutDegree int    `json:"out-degree"`
+}
+
+// LinkGraph holds the links between crawled pages and the URLs they link to,
+// along with the status code of the crawled URLs
+type LinkGraph struct {
+	Statuses map[string]int
+	Edges    []GraphEdge
+}
+
+// newLinkGraph returns the graph of the links of the crawled pages passed.
+// Fragments are ignored, as well as links from a page to itself and links
+// which are not http(s), such as 'mailto' or 'javascript' links.
+func newLinkGraph(results map[string]*HTTPResponse) *LinkGraph {
+	graph := &LinkGraph{Statuses: make(map[string]int)}
+	for url, result := range results {
+		graph.Statuses[url] = result.StatusCode
+		for _, link := range result.Links {
+			scheme := strings.ToLower(link.TargetURL.Scheme)
+			if scheme != "http" && scheme != "https" {
+				continue
+			}
+
+			target := link.pageURL()
+			if spiderURL(link.TargetURL) == normalizeURL(result.URL) {
+				continue
+			}
+
+			graph.Edges = append(graph.Edges, GraphEdge{
+				Source:   result.URL,
+				Target:   target,
+				Type:     link.Type.String(),
+				External: link.IsExternal,
+			})
+		}
+	}
+
+	graph.normalize()
+	return graph
+}
+
+// normalize sorts the edges, removes duplicates and sets their status
+func (graph *LinkGraph) normalize() {
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Type < b.Type
+	})
+
+	edges := graph.Edges[:0]
+	for i, edge := range graph.Edges {
+		if i > 0 && edge.Source == graph.Edges[i-1].Source && edge.Target == graph.Edges[i-1].Target &&
+			edge.Type == graph.Edges[i-1].Type {
+			continue
+		}
+		edge.Status = graph.Statuses[edge.Target]
+		edges = append(edges, edge)
+	}
+	graph.Edges = edges
+}
+
+// mergeLinkGraphs returns the union of the graphs passed. Statuses of the
+// second graph take precedence.
+func mergeLinkGraphs(graphA *LinkGraph, graphB *LinkGraph) *LinkGraph {
+	if graphA == nil {
+		return graphB
+	}
+	if graphB == nil {
+		return graphA
+	}
+
+	graph := &LinkGraph{Statuses: make(map[string]int)}
+	for _, source := range []*LinkGraph{graphA, graphB} {
+		for url, status := range source.Statuses {
+			graph.Statuses[url] = status
+		}
+		graph.Edges = append(graph.Edges, source.Edges...)
+	}
+
+	graph.normalize()
+	return graph
+}
+
+// Nodes returns the URLs of the graph with their degrees, sorted by URL
+func (graph *LinkGraph) Nodes() []GraphNode {
+	urls := make(map[string]bool)
+	inbound := make(map[string]map[string]bool)
+	outbound := make(map[string]map[string]bool)
+	for url := range graph.Statuses {
+		urls[url] = true
+	}
+	for _, edge := range graph.Edges {
+		urls[edge.Source] = true
+		urls[edge.Target] = true
+		if inbound[edge.Target] == nil {
+			inbound[edge.Target] = make(map[string]bool)
+		}
+		inbound[edge.Target][edge.Source] = true
+		if outbound[edge.Source] == nil {
+			outbound[edge.Source] = make(map[string]bool)
+		}
+		outbound[edge.Source][edge.Target] = true
+	}
+
+	nodes := make([]GraphNode, 0, len(urls))
+	for url := range urls {
+		nodes = append(nodes, GraphNode{
+			URL:       url,
+			Status:    graph.Statuses[url],
+			InDegree:  len(inbound[url]),
+			OutDegree: len(outbound[url]),
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].URL < nodes[j].URL
+	})
+
+	return nodes
+}
+
+// topNodes returns the nodes passed with the highest degree, at most count
+func topNodes(nodes []GraphNode, count int, degree func(GraphNode) int) []GraphNode {
+	sorted := make([]GraphNode, 0, len(nodes))
+	for _, node := range nodes {
+		if degree(node) > 0 {
+			sorted = append(sorted, node)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return degree(sorted[i]) > degree(sorted[j])
+	})
+
+	if len(sorted) > count {
+		sorted = sorted[:count]
+	}
+	return sorted
+}
+
+// GraphFormatFromPath returns the export format matching the extension of
+// the file path passed
+func GraphFormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return GraphFormatDOT, nil
+	case ".graphml":
+		return GraphFormatGraphML, nil
+	case ".json":
+		return GraphFormatJSON, nil
+	}
+
+	return "", fmt.Errorf("unknown link graph format for '%s', expected a .dot, .gv, .graphml or .json file", path)
+}
+
+// WriteLinkGraph exports the link graph to the file passed, in the format
+// matching its extension
+func WriteLinkGraph(path string, graph *LinkGraph) error {
+	format, err := GraphFormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	if graph == nil {
+		graph = &LinkGraph{}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	switch format {
+	case GraphFormatDOT:
+		err = writeDOTGraph(writer, graph)
+	case GraphFormatGraphML:
+		err = writeGraphMLGraph(writer, graph)
+	case GraphFormatJSON:
+		err = writeJSONGraph(writer, graph)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func writeDOTGraph(writer io.Writer, graph *LinkGraph) error {
+	fmt.Fprintln(writer, "digraph links {")
+	for _, node := range graph.Nodes() {
+		fmt.Fprintf(writer, "  %s [status=%d, in_degree=%d, out_degree=%d];\n",
+			dotQuote(node.URL), node.Status, node.InDegree, node.OutDegree)
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(writer, "  %s -> %s [type=%s, status=%d, external=%t];\n",
+			dotQuote(edge.Source), dotQuote(edge.Target), dotQuote(edge.Type), edge.Status, edge.External)
+	}
+	_, err := fmt.Fprintln(writer, "}")
+	return err
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+func writeGraphMLGraph(writer io.Writer, graph *LinkGraph) error {
+	document := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "status", For: "node", Name: "status", Type: "int"},
+			{ID: "in-degree", For: "node", Name: "in-degree", Type: "int"},
+			{ID: "out-degree", For: "node", Name: "out-degree", Type: "int"},
+			{ID: "type", For: "edge", Name: "type", Type: "string"},
+			{ID: "target-status", For: "edge", Name: "status", Type: "int"},
+			{ID: "external", For: "edge", Name: "external", Type: "boolean"},
+		},
+	}
+	document.Graph.ID = "links"
+	document.Graph.EdgeDefault = "directed"
+
+	for _, node := range graph.Nodes() {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{
+			ID: node.URL,
+			Data: []graphMLData{
+				{Key: "status", Value: fmt.Sprint(node.Status)},
+				{Key: "in-degree", Value: fmt.Sprint(node.InDegree)},
+				{Key: "out-degree", Value: fmt.Sprint(node.OutDegree)},
+			},
+		})
+	}
+	for _, edge := range graph.Edges {
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			Source: edge.Source,
+			Target: edge.Target,
+			Data: []graphMLData{
+				{Key: "type", Value: edge.Type},
+				{Key: "target-status", Value: fmt.Sprint(edge.Status)},
+				{Key: "external", Value: fmt.Sprint(edge.External)},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	return encoder.Encode(document)
+}
+
+func writeJSONGraph(writer io.Writer, graph *LinkGraph) error {
+	edges := graph.Edges
+	if edges == nil {
+		edges = []GraphEdge{}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Nodes []GraphNode `json:"nodes"`
+		Edges []GraphEdge `json:"edges"`
+	}{graph.Nodes(), edges})
+}
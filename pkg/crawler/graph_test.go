@@ -0,0 +1,127 @@
+package crawler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsyncCrawlLinkGraph(t *testing.T) {
+	pages := map[string]string{
+		"/": `<a href="/about">About</a><a href="/about#team">Team</a><a href="/">Home</a><img src="/logo.png">
+			<a href="mailto:sales@foo.bar">Mail</a><a href="tel:+33123456789">Call</a><a href="javascript:void(0)">Menu</a>`,
+		"/about": `<a href="/">Home</a><a href="/deleted">Deleted</a><a href="https://example.com/">Out</a>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>", page, "</body></html>")
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		Links:      CrawlPageLinksConfig{CrawlHyperlinks: true},
+		Graph:      true,
+	}
+
+	stats, _ := AsyncCrawl([]string{server.URL + "/", server.URL + "/about"}, config, make(chan struct{}))
+	if stats.Graph == nil {
+		t.Fatal("expected link graph to be recorded")
+	}
+
+	expected := []GraphEdge{
+		{Source: server.URL + "/", Target: server.URL + "/about", Type: "hyperlink", Status: 200},
+		{Source: server.URL + "/", Target: server.URL + "/logo.png", Type: "image"},
+		{Source: server.URL + "/about", Target: server.URL + "/", Type: "hyperlink", Status: 200},
+		{Source: server.URL + "/about", Target: server.URL + "/deleted", Type: "hyperlink", Status: 404},
+		{Source: server.URL + "/about", Target: "https://example.com/", Type: "hyperlink", External: true},
+	}
+	if !reflect.DeepEqual(stats.Graph.Edges, expected) {
+		t.Errorf("expected edges %+v, got %+v", expected, stats.Graph.Edges)
+	}
+
+	nodes := stats.Graph.Nodes()
+	if len(nodes) != 5 || nodes[0].URL != server.URL+"/" || nodes[0].InDegree != 1 || nodes[0].OutDegree != 2 {
+		t.Errorf("unexpected graph nodes %+v", nodes)
+	}
+}
+
+func TestWriteLinkGraph(t *testing.T) {
+	graph := mergeLinkGraphs(&LinkGraph{
+		Statuses: map[string]int{"https://foo.bar/": 200},
+		Edges: []GraphEdge{
+			{Source: "https://foo.bar/", Target: `https://foo.bar/"quoted"`, Type: "hyperlink"},
+		},
+	}, &LinkGraph{
+		Statuses: map[string]int{`https://foo.bar/"quoted"`: 404},
+		Edges: []GraphEdge{
+			{Source: "https://foo.bar/", Target: `https://foo.bar/"quoted"`, Type: "hyperlink"},
+			{Source: `https://foo.bar/"quoted"`, Target: "https://foo.bar/", Type: "hyperlink"},
+		},
+	})
+	if len(graph.Edges) != 2 || graph.Edges[0].Status != 404 {
+		t.Fatalf("expected merged graph edges to be unique with target status, got %+v", graph.Edges)
+	}
+
+	dir := t.TempDir()
+
+	dotPath := filepath.Join(dir, "links.dot")
+	if err := WriteLinkGraph(dotPath, graph); err != nil {
+		t.Fatal(err)
+	}
+	dot, _ := os.ReadFile(dotPath)
+	if !strings.HasPrefix(string(dot), "digraph links {") ||
+		!strings.Contains(string(dot), `"https://foo.bar/" -> "https://foo.bar/\"quoted\"" [type="hyperlink", status=404`) {
+		t.Errorf("unexpected DOT export:\n%s", dot)
+	}
+
+	graphMLPath := filepath.Join(dir, "links.graphml")
+	if err := WriteLinkGraph(graphMLPath, graph); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(graphMLPath)
+	var document graphMLDocument
+	if err := xml.Unmarshal(content, &document); err != nil {
+		t.Fatal(err)
+	}
+	if len(document.Graph.Nodes) != 2 || len(document.Graph.Edges) != 2 ||
+		document.Graph.Edges[1].Target != "https://foo.bar/" {
+		t.Errorf("unexpected GraphML export:\n%s", content)
+	}
+
+	jsonPath := filepath.Join(dir, "links.json")
+	if err := WriteLinkGraph(jsonPath, graph); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(jsonPath)
+	var edgeList struct {
+		Nodes []GraphNode `json:"nodes"`
+		Edges []GraphEdge `json:"edges"`
+	}
+	if err := json.Unmarshal(content, &edgeList); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(edgeList.Edges, graph.Edges) || edgeList.Nodes[0].InDegree != 1 {
+		t.Errorf("unexpected JSON export:\n%s", content)
+	}
+
+	if err := WriteLinkGraph(filepath.Join(dir, "links.txt"), graph); err == nil {
+		t.Errorf("expected unknown extension to be rejected")
+	}
+}
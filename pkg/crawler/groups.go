@@ -0,0 +1,147 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// OtherGroup is the group of URLs matching no configured group
+const OtherGroup = "other"
+
+// URLGroup gathers URLs whose statistics are reported together, selected by
+// a regular expression matched against the URL or a path prefix
+type URLGroup struct {
+	Name      string `json:"name"`
+	Pattern   string `json:"pattern"`
+	Prefix    string `json:"prefix"`
+	MaxTimeMs int    `json:"max-time-ms"`
+
+	pattern *regexp.Regexp
+}
+
+// GroupStats holds the crawling statistics of a group of URLs
+type GroupStats struct {
+	Total         int
+	StatusCodes   map[int]int
+	ResponseTimes Histogram
+	Unexpected    []CrawlResult
+}
+
+// Compile validates the group and parses its pattern. It must be called
+// before the group is used.
+func (group *URLGroup) Compile() (err error) {
+	if group.Name == "" {
+		return fmt.Errorf("URL group without name")
+	}
+	if group.Pattern == "" && group.Prefix == "" {
+		return fmt.Errorf("URL group '%s' has neither a pattern nor a prefix", group.Name)
+	}
+
+	if group.Pattern != "" {
+		group.pattern, err = regexp.Compile(group.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s' for URL group '%s': %v", group.Pattern, group.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// matches returns whether the URL, whose path is passed separately, belongs
+// to the group
+func (group URLGroup) matches(rawURL string, path string) bool {
+	if group.pattern != nil && !group.pattern.MatchString(rawURL) {
+		return false
+	}
+	return group.Prefix == "" || strings.HasPrefix(path, group.Prefix)
+}
+
+// LoadURLGroups reads and compiles a list of URL groups from a JSON file
+func LoadURLGroups(path string) (groups []URLGroup, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &groups)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL groups file '%s': %v", path, err)
+	}
+
+	for i := range groups {
+		err = groups[i].Compile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// GroupPolicy decides the group of crawled URLs. The first group matching a
+// URL applies. Otherwise, URLs are grouped by the first segment of their path
+// if ByPath is set, or in OtherGroup.
+type GroupPolicy struct {
+	Groups []URLGroup
+	ByPath bool
+}
+
+// IsEnabled returns whether URLs are grouped
+func (policy GroupPolicy) IsEnabled() bool {
+	return len(policy.Groups) > 0 || policy.ByPath
+}
+
+// GroupOf returns the name of the group the URL belongs to
+func (policy GroupPolicy) GroupOf(rawURL string) string {
+	path := "/"
+	if parsedURL, err := url.Parse(rawURL); err == nil && parsedURL.Path != "" {
+		path = parsedURL.Path
+	}
+
+	for _, group := range policy.Groups {
+		if group.matches(rawURL, path) {
+			return group.Name
+		}
+	}
+
+	if policy.ByPath {
+		segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+		return "/" + segments[0]
+	}
+
+	return OtherGroup
+}
+
+// SlowGroups returns the groups whose maximum response time exceeds their
+// configured limit
+func (policy GroupPolicy) SlowGroups(groupStats map[string]GroupStats) (slow []URLGroup) {
+	for _, group := range policy.Groups {
+		stats, ok := groupStats[group.Name]
+		if ok && group.MaxTimeMs > 0 && stats.ResponseTimes.Max > time.Duration(group.MaxTimeMs)*time.Millisecond {
+			slow = append(slow, group)
+		}
+	}
+
+	return
+}
+
+func (stats GroupStats) merge(other GroupStats) (merged GroupStats) {
+	merged.Total = stats.Total + other.Total
+	merged.StatusCodes = make(map[int]int)
+	for code, count := range stats.StatusCodes {
+		merged.StatusCodes[code] += count
+	}
+	for code, count := range other.StatusCodes {
+		merged.StatusCodes[code] += count
+	}
+	merged.ResponseTimes = stats.ResponseTimes.Merge(other.ResponseTimes)
+	merged.Unexpected = append(merged.Unexpected, stats.Unexpected...)
+	merged.Unexpected = append(merged.Unexpected, other.Unexpected...)
+
+	return
+}
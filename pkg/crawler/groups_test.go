@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGroupOf(t *testing.T) {
+	groups := []URLGroup{
+		{Name: "products", Prefix: "/products/"},
+		{Name: "blog", Pattern: `^https://blog\.foo\.bar/`},
+	}
+	for i := range groups {
+		if err := groups[i].Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		policy   GroupPolicy
+		url      string
+		expected string
+	}{
+		{GroupPolicy{Groups: groups}, "https://foo.bar/products/blue-widget", "products"},
+		{GroupPolicy{Groups: groups}, "https://blog.foo.bar/products/post", "products"},
+		{GroupPolicy{Groups: groups}, "https://blog.foo.bar/post", "blog"},
+		{GroupPolicy{Groups: groups}, "https://foo.bar/categories/widgets", OtherGroup},
+		{GroupPolicy{Groups: groups, ByPath: true}, "https://foo.bar/categories/widgets", "/categories"},
+		{GroupPolicy{ByPath: true}, "https://foo.bar", "/"},
+		{GroupPolicy{ByPath: true}, "https://foo.bar/about", "/about"},
+	}
+
+	for _, test := range tests {
+		if group := test.policy.GroupOf(test.url); group != test.expected {
+			t.Errorf("expected %s to be in group '%s', got '%s'", test.url, test.expected, group)
+		}
+	}
+}
+
+func TestURLGroupCompile(t *testing.T) {
+	invalid := []URLGroup{
+		{Prefix: "/products/"},
+		{Name: "products"},
+		{Name: "products", Pattern: "("},
+	}
+
+	for _, group := range invalid {
+		if err := group.Compile(); err == nil {
+			t.Errorf("expected group %+v to be invalid", group)
+		}
+	}
+}
+
+func TestAsyncCrawlGroups(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/products/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/blog/deleted":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	policy := GroupPolicy{
+		Groups: []URLGroup{{Name: "products", Prefix: "/products/", MaxTimeMs: 30}},
+		ByPath: true,
+	}
+	for i := range policy.Groups {
+		policy.Groups[i].Compile()
+	}
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		Groups:     policy,
+	}
+
+	urls := []string{server.URL + "/products/slow", server.URL + "/products/fast",
+		server.URL + "/blog/post", server.URL + "/blog/deleted"}
+	stats, _ := AsyncCrawl(urls, config, make(chan struct{}))
+
+	products, blog := stats.Groups["products"], stats.Groups["/blog"]
+	if products.Total != 2 || products.StatusCodes[200] != 2 || len(products.Unexpected) != 0 {
+		t.Errorf("unexpected products group stats %+v", products)
+	}
+	if blog.Total != 2 || blog.StatusCodes[404] != 1 || len(blog.Unexpected) != 1 {
+		t.Errorf("unexpected blog group stats %+v", blog)
+	}
+	if blog.ResponseTimes.Count != 1 {
+		t.Errorf("expected only 200 responses to be timed, got %d", blog.ResponseTimes.Count)
+	}
+
+	if slow := policy.SlowGroups(stats.Groups); len(slow) != 1 || slow[0].Name != "products" {
+		t.Errorf("expected products group to be too slow, got %v", slow)
+	}
+
+	merged := MergeCrawlStats(stats, stats)
+	if merged.Groups["/blog"].Total != 4 || len(merged.Groups["/blog"].Unexpected) != 2 {
+		t.Errorf("expected group stats to be merged, got %+v", merged.Groups["/blog"])
+	}
+}
@@ -0,0 +1,164 @@
+package crawler
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// histogramSubBuckets is the number of linear buckets each power of two is
+// divided in, which bounds the relative error of percentiles to 1/16
+const histogramSubBuckets = 16
+
+// Histogram records durations with millisecond resolution in log-linear
+// buckets, so that percentiles can be computed with a bounded error and
+// histograms of successive crawls merged together.
+type Histogram struct {
+	Counts []int64
+	Count  int64
+	Sum    time.Duration
+	Max    time.Duration
+}
+
+// HistogramBucket is a range of durations and the number of values recorded
+// in it
+type HistogramBucket struct {
+	Lower time.Duration
+	Upper time.Duration
+	Count int64
+}
+
+// histogramIndex returns the index of the bucket holding value
+func histogramIndex(value int64) int {
+	if value < histogramSubBuckets {
+		return int(value)
+	}
+
+	exponent := bits.Len64(uint64(value)) - 1
+	shift := exponent - 4
+	return (exponent-3)*histogramSubBuckets + int(value>>uint(shift)) - histogramSubBuckets
+}
+
+// histogramBounds returns the lower and upper bounds of the bucket at index,
+// the upper bound being excluded
+func histogramBounds(index int) (lower int64, upper int64) {
+	if index < histogramSubBuckets {
+		return int64(index), int64(index) + 1
+	}
+
+	shift := uint(index/histogramSubBuckets - 1)
+	sub := int64(index%histogramSubBuckets + histogramSubBuckets)
+	return sub << shift, (sub + 1) << shift
+}
+
+// Record adds a duration to the histogram
+func (histogram *Histogram) Record(value time.Duration) {
+	if value < 0 {
+		value = 0
+	}
+
+	index := histogramIndex(int64(value / time.Millisecond))
+	if index >= len(histogram.Counts) {
+		counts := make([]int64, index+1)
+		copy(counts, histogram.Counts)
+		histogram.Counts = counts
+	}
+
+	histogram.Counts[index]++
+	histogram.Count++
+	histogram.Sum += value
+	if value > histogram.Max {
+		histogram.Max = value
+	}
+}
+
+// Merge returns a histogram holding the values of both histograms
+func (histogram Histogram) Merge(other Histogram) (merged Histogram) {
+	size := len(histogram.Counts)
+	if len(other.Counts) > size {
+		size = len(other.Counts)
+	}
+
+	merged.Counts = make([]int64, size)
+	for i, count := range histogram.Counts {
+		merged.Counts[i] += count
+	}
+	for i, count := range other.Counts {
+		merged.Counts[i] += count
+	}
+
+	merged.Count = histogram.Count + other.Count
+	merged.Sum = histogram.Sum + other.Sum
+	merged.Max = histogram.Max
+	if other.Max > merged.Max {
+		merged.Max = other.Max
+	}
+
+	return
+}
+
+// Mean returns the average of the values recorded
+func (histogram Histogram) Mean() time.Duration {
+	if histogram.Count == 0 {
+		return 0
+	}
+	return histogram.Sum / time.Duration(histogram.Count)
+}
+
+// Percentile returns the duration below which the percentage of values
+// passed falls, from 0 to 100
+func (histogram Histogram) Percentile(percentile float64) time.Duration {
+	if histogram.Count == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(percentile / 100 * float64(histogram.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var cumulated int64
+	for index, count := range histogram.Counts {
+		cumulated += count
+		if cumulated >= rank {
+			_, upper := histogramBounds(index)
+			value := time.Duration(upper-1) * time.Millisecond
+			if value > histogram.Max {
+				value = histogram.Max
+			}
+			return value
+		}
+	}
+
+	return histogram.Max
+}
+
+// Buckets returns the number of values recorded per power of two
+// milliseconds, from the first to the last non-empty range
+func (histogram Histogram) Buckets() (buckets []HistogramBucket) {
+	for index, count := range histogram.Counts {
+		lower, upper := histogramBounds(index)
+		if lower > 0 {
+			lower = 1 << uint(bits.Len64(uint64(lower))-1)
+			upper = lower * 2
+		}
+
+		bucketLower := time.Duration(lower) * time.Millisecond
+		if len(buckets) == 0 || buckets[len(buckets)-1].Lower != bucketLower {
+			buckets = append(buckets, HistogramBucket{
+				Lower: bucketLower,
+				Upper: time.Duration(upper) * time.Millisecond,
+			})
+		}
+		buckets[len(buckets)-1].Count += count
+	}
+
+	for len(buckets) > 0 && buckets[0].Count == 0 {
+		buckets = buckets[1:]
+	}
+	for len(buckets) > 0 && buckets[len(buckets)-1].Count == 0 {
+		buckets = buckets[:len(buckets)-1]
+	}
+
+	return
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramIndexBounds(t *testing.T) {
+	for value := int64(0); value < 100000; value++ {
+		lower, upper := histogramBounds(histogramIndex(value))
+		if value < lower || value >= upper {
+			t.Fatalf("value %d outside of its bucket [%d, %d)", value, lower, upper)
+		}
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	var histogram Histogram
+	for i := 1; i <= 100; i++ {
+		histogram.Record(time.Duration(i*10) * time.Millisecond)
+	}
+
+	tests := map[float64]time.Duration{
+		50:  500 * time.Millisecond,
+		90:  900 * time.Millisecond,
+		99:  990 * time.Millisecond,
+		100: 1000 * time.Millisecond,
+	}
+	for percentile, expected := range tests {
+		value := histogram.Percentile(percentile)
+		if value < expected || value > expected+expected/histogramSubBuckets {
+			t.Errorf("expected p%.0f to be about %s, got %s", percentile, expected, value)
+		}
+	}
+
+	if histogram.Max != time.Second || histogram.Count != 100 {
+		t.Errorf("unexpected max %s or count %d", histogram.Max, histogram.Count)
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	var fast, slow Histogram
+	for i := 0; i < 90; i++ {
+		fast.Record(20 * time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		slow.Record(3 * time.Second)
+	}
+
+	merged := fast.Merge(slow)
+	if merged.Count != 100 || merged.Max != 3*time.Second {
+		t.Errorf("unexpected merged count %d or max %s", merged.Count, merged.Max)
+	}
+	if p50 := merged.Percentile(50); p50 != 20*time.Millisecond {
+		t.Errorf("expected p50 of 20ms, got %s", p50)
+	}
+	if p95 := merged.Percentile(95); p95 < 3*time.Second {
+		t.Errorf("expected p95 to reflect slow responses, got %s", p95)
+	}
+	if len(fast.Counts) != histogramIndex(20)+1 {
+		t.Errorf("expected merged histograms not to be modified")
+	}
+
+	buckets := merged.Buckets()
+	if buckets[0].Lower != 16*time.Millisecond || buckets[0].Count != 90 {
+		t.Errorf("unexpected first bucket %+v", buckets[0])
+	}
+	if last := buckets[len(buckets)-1]; last.Upper != 4096*time.Millisecond || last.Count != 10 {
+		t.Errorf("unexpected last bucket %+v", last)
+	}
+}
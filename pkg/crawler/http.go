@@ -1,9 +1,13 @@
 package crawler
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -19,23 +23,147 @@ type HTTPResponse struct {
 	StatusCode int
 	EndTime    time.Time
 	Err        error
-	Links      []Link
+
+	// BodyEndTime is the time at which the response body was read
+	BodyEndTime time.Time
+
+	Links    []Link
+	BodySize int64
+
+	// Anchors holds the fragments html pages can be linked to with
+	Anchors    map[string]bool
+	Canonicals []CanonicalLink
+
+	AssertionFailures []string
+	Fingerprint       *PageFingerprint
+	SecurityIssues    []string
+	Cache             *CacheInfo
+
+	RevalidationIssues []string
+	MixedContent       []string
+
+	SEO *PageSEO
+
+	Compression       *CompressionInfo
+	CompressionIssues []string
 }
 
+// BodyPolicy defines how much of the response body is downloaded
+type BodyPolicy int
+
+const (
+	// ReadFullBody downloads the complete response body
+	ReadFullBody BodyPolicy = 0
+	// DiscardBody closes the response without downloading its body
+	DiscardBody BodyPolicy = 1
+	// ReadBodyPrefix downloads the first HTTPConfig.BodyLimit bytes of the
+	// response body
+	ReadBodyPrefix BodyPolicy = 2
+)
+
+// MethodHeadThenGet issues a HEAD request, followed by a GET request if the
+// server does not support HEAD requests for the URL
+const MethodHeadThenGet = "HEAD-GET"
+
 // HTTPConfig hold settings used to get pages via HTTP/S
 type HTTPConfig struct {
-	User       string
-	Pass       string
-	Timeout    time.Duration
-	ParseLinks bool
+	User        string
+	Pass        string
+	Timeout     time.Duration
+	ParseLinks  bool
+	Transport   http.RoundTripper
+	Method      string
+	Body        BodyPolicy
+	BodyLimit   int64
+	Assertions  []Assertion
+	Fingerprint bool
+	NoRedirects bool
+	Headers     http.Header
+
+	ParseStylesheets bool
+	ParseAnchors     bool
+
+	CheckMixedContent bool
+	CheckCanonicals   bool
+	AuditSEO          bool
+
+	AuditSecurity   bool
+	RequiredHeaders []string
+
+	CacheReport       bool
+	CacheStatusHeader string
+
+	CheckRevalidation bool
+
+	CompressionReport  bool
+	CompressionMinSize int64
 }
 
 // HTTPGetter performs a single HTTP/S  to the url, and return information
 // related to the result as an HTTPResponse
 type HTTPGetter func(client *http.Client, url string, config HTTPConfig) (response *HTTPResponse)
 
-func createRequest(url string) (*http.Request, *httpstat.Result, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// ParseBodyPolicy converts a body policy description into a BodyPolicy
+// and its limit. Valid values are "full", "discard" or a number of bytes.
+func ParseBodyPolicy(value string) (policy BodyPolicy, limit int64, err error) {
+	switch strings.ToLower(value) {
+	case "", "full":
+		return ReadFullBody, 0, nil
+	case "discard":
+		return DiscardBody, 0, nil
+	}
+
+	limit, err = strconv.ParseInt(value, 10, 64)
+	if err != nil || limit <= 0 {
+		return ReadFullBody, 0, fmt.Errorf("invalid body policy '%s'", value)
+	}
+
+	return ReadBodyPrefix, limit, nil
+}
+
+// Validate returns an error if the configuration passed can not be honoured,
+// such as body assertions when bodies are not downloaded
+func (config HTTPConfig) Validate() error {
+	if (config.Method != "" && config.Method != http.MethodGet) || config.Body == DiscardBody {
+		for i := range config.Assertions {
+			if config.Assertions[i].NeedsBody() {
+				return errors.New("body assertions can not be checked without downloading page bodies," +
+					" use the GET method and a 'full' or limited body policy")
+			}
+		}
+	}
+
+	if config.Body == ReadBodyPrefix {
+		if config.CompressionReport {
+			return errors.New("compression can not be reported when only a prefix of bodies is downloaded," +
+				" use a 'full' body policy")
+		}
+		for i := range config.Assertions {
+			if config.Assertions[i].MinSize > 0 || config.Assertions[i].MaxSize > 0 {
+				return errors.New("body sizes can not be checked when only a prefix of bodies is downloaded," +
+					" use a 'full' body policy")
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseMethod converts a method description into the HTTP method to use.
+// Valid values are "GET", "HEAD" or "HEAD-GET", regardless of their case.
+func ParseMethod(value string) (string, error) {
+	switch method := strings.ToUpper(value); method {
+	case "", http.MethodGet:
+		return http.MethodGet, nil
+	case http.MethodHead, MethodHeadThenGet:
+		return method, nil
+	}
+
+	return "", fmt.Errorf("invalid method '%s', expected GET, HEAD or HEAD-GET", value)
+}
+
+func createRequest(method string, url string) (*http.Request, *httpstat.Result, error) {
+	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
 		log.Error(err)
 		return nil, nil, err
@@ -50,35 +178,93 @@ func createRequest(url string) (*http.Request, *httpstat.Result, error) {
 }
 
 func configureRequest(req *http.Request, config HTTPConfig) {
+	for name, values := range config.Headers {
+		req.Header[name] = values
+	}
+
+	// Setting Accept-Encoding disables transparent decompression, so that
+	// the encoding and size of responses can be measured
+	if config.CompressionReport && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", DefaultAcceptEncoding)
+	}
+
 	if len(config.User) > 0 {
 		req.SetBasicAuth(config.User, config.Pass)
 	}
 }
 
-// HTTPGet issues a GET request to a single URL and returns an HTTPResponse
+func doRequest(client *http.Client, method string, urlStr string, config HTTPConfig) (*http.Response,
+	*httpstat.Result, error) {
+	if method == MethodHeadThenGet {
+		resp, result, err := doRequest(client, http.MethodHead, urlStr, config)
+		if err != nil || (resp.StatusCode != http.StatusMethodNotAllowed &&
+			resp.StatusCode != http.StatusNotImplemented) {
+			return resp, result, err
+		}
+
+		resp.Body.Close()
+		return doRequest(client, http.MethodGet, urlStr, config)
+	}
+
+	req, result, err := createRequest(method, urlStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	configureRequest(req, config)
+
+	resp, err := client.Do(req)
+	return resp, result, err
+}
+
+// discardDrainLimit is the number of bytes of a discarded body read before
+// closing it, so that the connection can be reused for small bodies
+const discardDrainLimit = 256 << 10
+
+// readBody consumes the response body according to the body policy, and
+// returns the content read if keepContent is true
+func readBody(body io.Reader, config HTTPConfig, keepContent bool) (content []byte, size int64, err error) {
+	switch config.Body {
+	case DiscardBody:
+		io.Copy(io.Discard, io.LimitReader(body, discardDrainLimit))
+		return nil, 0, nil
+	case ReadBodyPrefix:
+		body = io.LimitReader(body, config.BodyLimit)
+	}
+
+	if !keepContent {
+		size, err = io.Copy(io.Discard, body)
+		return
+	}
+
+	content, err = io.ReadAll(body)
+	return content, int64(len(content)), err
+}
+
+// HTTPGet issues a GET request to a single URL and returns an HTTPResponse.
+// The HTTP method used can be changed with HTTPConfig.Method.
 func HTTPGet(client *http.Client, urlStr string, config HTTPConfig) (response *HTTPResponse) {
 	response = &HTTPResponse{
 		URL: urlStr,
 	}
 
-	req, result, err := createRequest(urlStr)
-	if err != nil {
+	method := config.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	resp, result, err := doRequest(client, method, urlStr, config)
+	if resp == nil && result == nil {
 		response.Err = err
 		return
 	}
 
-	configureRequest(req, config)
-
-	resp, err := client.Do(req)
 	response.EndTime = time.Now()
 	response.Response = resp
 	response.Result = result
 
 	defer func() {
 		if resp != nil {
-			if !config.ParseLinks {
-				io.Copy(io.Discard, resp.Body)
-			}
 			resp.Body.Close()
 		}
 		PrintResult(response)
@@ -97,18 +283,146 @@ func HTTPGet(client *http.Client, urlStr string, config HTTPConfig) (response *H
 		return
 	}
 
-	if config.ParseLinks {
+	if config.AuditSecurity && isHTML(resp.Header) {
+		required := config.RequiredHeaders
+		if required == nil {
+			required = DefaultRequiredSecurityHeaders
+		}
+		response.SecurityIssues = AuditSecurityHeaders(resp.Header, resp.Request.URL.Scheme == "https", required)
+	}
+
+	if config.CacheReport {
+		cacheInfo := ParseCacheInfo(resp.Header, config.CacheStatusHeader)
+		response.Cache = &cacheInfo
+	}
+
+	// Responses are only decompressed transparently when the
+	// Accept-Encoding header was not set explicitly
+	encoding := resp.Header.Get("Content-Encoding")
+	decode := encoding != "" && !resp.Uncompressed
+
+	// Mixed content is checked on every https page and stylesheet crawled,
+	// including those whose links are not collected
+	checkMixedContent := config.CheckMixedContent && resp.Request.URL.Scheme == "https" &&
+		(isHTML(resp.Header) || isCSS(resp.Header))
+	keepContent := config.ParseLinks || len(config.Assertions) > 0 || config.Fingerprint ||
+		(config.CompressionReport && decode) || (config.ParseStylesheets && isCSS(resp.Header)) ||
+		((config.ParseAnchors || config.CheckCanonicals || config.AuditSEO) && isHTML(resp.Header)) ||
+		checkMixedContent
+	content, size, err := readBody(resp.Body, config, keepContent)
+	response.BodyEndTime = time.Now()
+	response.BodySize = size
+	if err != nil {
+		log.Error("error reading response body:", err)
+		return
+	}
+
+	decoded := !decode
+	if config.CompressionReport {
+		response.Compression = newCompressionInfo(resp.Header, size)
+		defer func() {
+			if decoded {
+				response.Compression.BodySize = size
+			}
+			response.CompressionIssues = response.Compression.compressionIssues(
+				resp.Request.Header.Get("Accept-Encoding"), config.CompressionMinSize)
+		}()
+	}
+
+	if config.CheckRevalidation && response.StatusCode == http.StatusOK {
+		response.RevalidationIssues = checkRevalidation(client, urlStr, config, resp.Header)
+	}
+
+	if decode && content != nil {
+		content, err = decodeContent(content, encoding)
+		if err != nil {
+			log.Warn("body of ", urlStr, " not analysed: ", err)
+			// Assertions would otherwise pass without being checked
+			if response.StatusCode/100 == 2 {
+				for i := range config.Assertions {
+					if config.Assertions[i].Applies(urlStr) {
+						response.AssertionFailures = []string{"body can not be decoded: " + err.Error()}
+						break
+					}
+				}
+			}
+			return
+		}
+		// BodySize remains the size transferred, the decoded size being
+		// reported by the compression information
+		size = int64(len(content))
+		decoded = true
+	}
+
+	body := &page{content: content}
+	if len(config.Assertions) > 0 && response.StatusCode/100 == 2 {
+		response.AssertionFailures = checkAssertions(config.Assertions, urlStr, resp.Header, body, size)
+	}
+
+	if config.Fingerprint && response.StatusCode/100 == 2 && isHTML(resp.Header) {
+		doc, err := body.document()
+		if err == nil {
+			fingerprint := FingerprintDocument(doc)
+			response.Fingerprint = &fingerprint
+		}
+	}
+
+	if config.ParseAnchors && response.StatusCode/100 == 2 && isHTML(resp.Header) {
+		doc, err := body.document()
+		if err == nil {
+			response.Anchors = documentAnchors(doc)
+		}
+	}
+
+	if config.CheckCanonicals && response.StatusCode/100 == 2 && isHTML(resp.Header) {
+		doc, err := body.document()
+		if err == nil {
+			response.Canonicals = documentCanonicals(doc, *resp.Request.URL)
+		}
+	}
+
+	if config.AuditSEO && response.StatusCode/100 == 2 && isHTML(resp.Header) {
+		doc, err := body.document()
+		if err == nil {
+			response.SEO = auditDocumentSEO(doc, resp.Header)
+		}
+	}
+
+	if (config.ParseLinks || config.ParseStylesheets || checkMixedContent) && content != nil {
 		currentURL, err := url.Parse(urlStr)
 		if err != nil {
 			log.Error("error parsing base URL:", err)
 			return
 		}
 
-		response.Links, err = ExtractLinks(resp.Body, *currentURL)
+		if isCSS(resp.Header) {
+			links := ExtractStylesheetLinks(content, *currentURL)
+			if checkMixedContent {
+				response.MixedContent = findMixedContent(links)
+			}
+			if config.ParseStylesheets {
+				response.Links = links
+			}
+			return
+		}
+		if !config.ParseLinks && !checkMixedContent {
+			return
+		}
+
+		doc, err := body.document()
 		if err != nil {
 			log.Error("error extracting page links:", err)
 			return
 		}
+		links := ExtractDocumentLinks(doc, *currentURL)
+		if checkMixedContent {
+			response.MixedContent = findMixedContent(links)
+		}
+		// Links of pages which are not explored are not kept, so that
+		// linked pages are not crawled recursively
+		if config.ParseLinks {
+			response.Links = links
+		}
 	}
 
 	return
@@ -144,9 +458,16 @@ func RunConcurrentGet(httpGet HTTPGetter, urls []string, config HTTPConfig,
 	var wg sync.WaitGroup
 	clientsReady := make(chan *http.Client, maxConcurrent)
 	for i := 0; i < maxConcurrent; i++ {
-		clientsReady <- &http.Client{
-			Timeout: config.Timeout,
+		client := &http.Client{
+			Timeout:   config.Timeout,
+			Transport: config.Transport,
 		}
+		if config.NoRedirects {
+			client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			}
+		}
+		clientsReady <- client
 	}
 
 	defer func() {
@@ -177,22 +498,48 @@ func RunConcurrentGet(httpGet HTTPGetter, urls []string, config HTTPConfig,
 // PrintResult will print information relative to the HTTPResponse
 func PrintResult(result *HTTPResponse) {
 	total := int(result.Result.Total(result.EndTime).Round(time.Millisecond) / time.Millisecond)
+	transferEnd := result.BodyEndTime
+	if transferEnd.IsZero() {
+		transferEnd = result.EndTime
+	}
 
+	var fields log.Fields
 	if log.GetLevel() == log.DebugLevel {
-		log.WithFields(log.Fields{
+		fields = log.Fields{
 			"status":  result.StatusCode,
 			"dns":     int(result.Result.DNSLookup / time.Millisecond),
 			"tcpconn": int(result.Result.TCPConnection / time.Millisecond),
 			"tls":     int(result.Result.TLSHandshake / time.Millisecond),
 			"server":  int(result.Result.ServerProcessing / time.Millisecond),
-			"content": int(result.Result.ContentTransfer(result.EndTime) / time.Millisecond),
+			"content": int(result.Result.ContentTransfer(transferEnd) / time.Millisecond),
 			"time":    total,
+			"size":    result.BodySize,
 			"close":   result.EndTime,
-		}).Debug("url=" + result.URL)
+		}
 	} else {
-		log.WithFields(log.Fields{
+		fields = log.Fields{
 			"status":     result.StatusCode,
 			"total-time": total,
-		}).Info("url=" + result.URL)
+			"size":       result.BodySize,
+		}
+	}
+
+	if result.Cache != nil {
+		fields["cache"] = result.Cache.Result
+		if log.GetLevel() == log.DebugLevel {
+			fields["cache-control"] = result.Cache.CacheControl
+			fields["age"] = result.Cache.Age
+		}
+	}
+
+	if result.Compression != nil {
+		fields["encoding"] = result.Compression.Encoding
+		fields["transfer-size"] = result.Compression.TransferSize
+	}
+
+	if log.GetLevel() == log.DebugLevel {
+		log.WithFields(fields).Debug("url=" + result.URL)
+	} else {
+		log.WithFields(fields).Info("url=" + result.URL)
 	}
 }
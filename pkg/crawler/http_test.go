@@ -1,10 +1,12 @@
 package crawler
 
 import (
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -208,3 +210,187 @@ func mustParseURL(rawurl string) url.URL {
 	}
 	return *parsedURL
 }
+
+func TestHTTPGetMethodAndBodyPolicy(t *testing.T) {
+	body := "0123456789abcdefghij"
+	tests := []struct {
+		name            string
+		config          HTTPConfig
+		allowHead       bool
+		expectedMethods []string
+		expectedSize    int64
+	}{
+		{
+			name:            "GET with full body",
+			config:          HTTPConfig{},
+			expectedMethods: []string{"GET"},
+			expectedSize:    20,
+		},
+		{
+			name:            "GET with discarded body",
+			config:          HTTPConfig{Body: DiscardBody},
+			expectedMethods: []string{"GET"},
+			expectedSize:    0,
+		},
+		{
+			name:            "GET with body prefix",
+			config:          HTTPConfig{Body: ReadBodyPrefix, BodyLimit: 5},
+			expectedMethods: []string{"GET"},
+			expectedSize:    5,
+		},
+		{
+			name:            "HEAD",
+			config:          HTTPConfig{Method: "HEAD"},
+			allowHead:       true,
+			expectedMethods: []string{"HEAD"},
+			expectedSize:    0,
+		},
+		{
+			name:            "HEAD then GET, HEAD allowed",
+			config:          HTTPConfig{Method: MethodHeadThenGet},
+			allowHead:       true,
+			expectedMethods: []string{"HEAD"},
+			expectedSize:    0,
+		},
+		{
+			name:            "HEAD then GET, HEAD not allowed",
+			config:          HTTPConfig{Method: MethodHeadThenGet},
+			expectedMethods: []string{"HEAD", "GET"},
+			expectedSize:    20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var methods []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				methods = append(methods, r.Method)
+				if r.Method == http.MethodHead && !tt.allowHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			response := HTTPGet(&http.Client{Timeout: 5 * time.Second}, server.URL, tt.config)
+
+			if response.StatusCode != http.StatusOK {
+				t.Errorf("expected status code 200, got: %d", response.StatusCode)
+			}
+			if !testEq(methods, tt.expectedMethods) {
+				t.Errorf("expected methods %v, got %v", tt.expectedMethods, methods)
+			}
+			if response.BodySize != tt.expectedSize {
+				t.Errorf("expected body size %d, got %d", tt.expectedSize, response.BodySize)
+			}
+		})
+	}
+}
+
+func TestHTTPGetDiscardedBodyReusesConnection(t *testing.T) {
+	var connections int
+	var mutex sync.Mutex
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The end of the body is delayed, so that it is not drained when
+		// the response is closed
+		w.Write([]byte(strings.Repeat("0123456789", 100)))
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(strings.Repeat("0123456789", 100)))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mutex.Lock()
+			connections++
+			mutex.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	for i := 0; i < 3; i++ {
+		HTTPGet(client, server.URL, HTTPConfig{Body: DiscardBody})
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if connections != 1 {
+		t.Errorf("expected discarded bodies to keep the connection alive, got %d connections", connections)
+	}
+}
+
+func TestHTTPConfigValidate(t *testing.T) {
+	sizeAssertion := []Assertion{{MaxSize: 1024}}
+	headerAssertion := []Assertion{{ContentType: "text/html"}}
+	tests := []struct {
+		config HTTPConfig
+		valid  bool
+	}{
+		{config: HTTPConfig{}, valid: true},
+		{config: HTTPConfig{Method: http.MethodHead, Assertions: headerAssertion}, valid: true},
+		{config: HTTPConfig{Method: http.MethodHead, Assertions: sizeAssertion}},
+		{config: HTTPConfig{Body: DiscardBody, Assertions: sizeAssertion}},
+		{config: HTTPConfig{Body: ReadBodyPrefix, BodyLimit: 10}, valid: true},
+		{config: HTTPConfig{Assertions: sizeAssertion}, valid: true},
+		{config: HTTPConfig{Body: ReadBodyPrefix, BodyLimit: 10, Assertions: sizeAssertion}},
+		{config: HTTPConfig{Body: ReadBodyPrefix, BodyLimit: 10, CompressionReport: true}},
+	}
+
+	for i, tt := range tests {
+		if err := tt.config.Validate(); (err == nil) != tt.valid {
+			t.Errorf("expected configuration %d to be valid: %t, got error %v", i, tt.valid, err)
+		}
+	}
+}
+
+func TestParseBodyPolicy(t *testing.T) {
+	tests := []struct {
+		value          string
+		expectedPolicy BodyPolicy
+		expectedLimit  int64
+		expectError    bool
+	}{
+		{value: "full", expectedPolicy: ReadFullBody},
+		{value: "", expectedPolicy: ReadFullBody},
+		{value: "discard", expectedPolicy: DiscardBody},
+		{value: "1024", expectedPolicy: ReadBodyPrefix, expectedLimit: 1024},
+		{value: "-3", expectError: true},
+		{value: "some", expectError: true},
+	}
+
+	for _, tt := range tests {
+		policy, limit, err := ParseBodyPolicy(tt.value)
+		if (err != nil) != tt.expectError {
+			t.Errorf("%s: expected error: %v, got: %v", tt.value, tt.expectError, err)
+			continue
+		}
+		if policy != tt.expectedPolicy || limit != tt.expectedLimit {
+			t.Errorf("%s: expected policy %d/%d, got %d/%d", tt.value, tt.expectedPolicy,
+				tt.expectedLimit, policy, limit)
+		}
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	tests := map[string]string{
+		"":         http.MethodGet,
+		"get":      http.MethodGet,
+		"HEAD":     http.MethodHead,
+		"head-get": MethodHeadThenGet,
+	}
+	for value, expected := range tests {
+		method, err := ParseMethod(value)
+		if err != nil || method != expected {
+			t.Errorf("%s: expected method %s, got %s (%v)", value, expected, method, err)
+		}
+	}
+
+	for _, value := range []string{"GTE", "POST", "HEAD GET"} {
+		if _, err := ParseMethod(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
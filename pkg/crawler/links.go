@@ -15,15 +15,80 @@ type LinkType int
 const (
 	// Hyperlink is html 'a' tag
 	Hyperlink LinkType = 0
-	// Image is html 'img' tag
+	// Image is html 'img' tag, or 'source' tag of a 'picture'
 	Image LinkType = 1
+	// Stylesheet is html 'link' tag with a 'stylesheet' relation
+	Stylesheet LinkType = 2
+	// Icon is html 'link' tag with an 'icon' relation, such as favicons
+	Icon LinkType = 3
+	// Preload is html 'link' tag with a 'preload', 'prefetch' or
+	// 'modulepreload' relation
+	Preload LinkType = 4
+	// Canonical is html 'link' tag with a 'canonical' relation
+	Canonical LinkType = 5
+	// Alternate is html 'link' tag with an 'alternate' relation
+	Alternate LinkType = 6
+	// Script is html 'script' tag
+	Script LinkType = 7
+	// Frame is html 'iframe' tag
+	Frame LinkType = 8
+	// Media is html 'video' or 'audio' tag, their 'source' tags and video
+	// posters
+	Media LinkType = 9
+	// Object is html 'object' tag
+	Object LinkType = 10
+	// Form is html 'form' tag submitted with the GET method
+	Form LinkType = 11
+	// Refresh is html 'meta' tag with a 'refresh' http-equiv
+	Refresh LinkType = 12
+	// StylesheetResource is a css 'url()' reference, such as a background
+	// image or a web font
+	StylesheetResource LinkType = 13
 )
 
+var linkTypeNames = map[LinkType]string{
+	Hyperlink:          "hyperlink",
+	Image:              "image",
+	Stylesheet:         "stylesheet",
+	Icon:               "icon",
+	Preload:            "preload",
+	Canonical:          "canonical",
+	Alternate:          "alternate",
+	Script:             "script",
+	Frame:              "iframe",
+	Media:              "media",
+	Object:             "object",
+	Form:               "form",
+	Refresh:            "refresh",
+	StylesheetResource: "stylesheet-resource",
+}
+
+// String returns the name of the link type, such as "image"
+func (linkType LinkType) String() string {
+	if name, ok := linkTypeNames[linkType]; ok {
+		return name
+	}
+	return "unknown"
+}
+
 // Link type holds information of URL links
 type Link struct {
 	Type       LinkType
 	TargetURL  url.URL
 	IsExternal bool
+	// NoFollow is set for hyperlinks with a 'nofollow' or 'sponsored'
+	// relation
+	NoFollow bool
+	// Destination is the 'as' attribute of preload links, such as "image"
+	Destination string
+}
+
+// pageURL returns the URL targeted by the link, without its fragment
+func (link Link) pageURL() string {
+	target := link.TargetURL
+	target.Fragment = ""
+	target.RawFragment = ""
+	return target.String()
 }
 
 // RewriteURLHost modifies a list of raw URL strings to point to a new host.
@@ -50,34 +115,74 @@ func ExtractLinks(htmlBody io.ReadCloser, currentURL url.URL) ([]Link, error) {
 		return nil, err
 	}
 
+	return ExtractDocumentLinks(doc, currentURL), nil
+}
+
+// ExtractDocumentLinks returns links found in the parsed html document
+// provided. See ExtractLinks. Relative links are resolved against the
+// document 'base' URL if any.
+func ExtractDocumentLinks(doc *goquery.Document, currentURL url.URL) []Link {
 	links := extractALinks(doc)
 	links = append(links, extractImageLinks(doc)...)
+	links = append(links, extractLinkTagLinks(doc)...)
+	links = append(links, extractAttributeLinks(doc, "script[src]", "src", Script)...)
+	links = append(links, extractAttributeLinks(doc, "iframe[src]", "src", Frame)...)
+	links = append(links, extractMediaLinks(doc)...)
+	links = append(links, extractAttributeLinks(doc, "object[data]", "data", Object)...)
+	links = append(links, extractFormLinks(doc)...)
+	links = append(links, extractRefreshLinks(doc)...)
 
-	for index := range links {
-		links[index].IsExternal = links[index].TargetURL.IsAbs() &&
-			links[index].TargetURL.Host != currentURL.Host
+	resolveLinks(links, documentBaseURL(doc, currentURL), currentURL)
+	return links
+}
+
+// documentBaseURL returns the URL relative links of the document are resolved
+// against: the first 'base' tag URL, or the document URL
+func documentBaseURL(doc *goquery.Document, currentURL url.URL) url.URL {
+	href, ok := doc.Find("base[href]").First().Attr("href")
+	if !ok {
+		return currentURL
+	}
+
+	baseURL, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		log.Warn("Invalid base URL '", href, "' in ", currentURL.String(), ": ", err)
+		return currentURL
+	}
+
+	return *currentURL.ResolveReference(baseURL)
+}
 
+// resolveLinks resolves relative links against the base URL passed, and
+// flags HTTP links to other hosts than the document one as external
+func resolveLinks(links []Link, baseURL url.URL, currentURL url.URL) {
+	for index := range links {
 		if !links[index].TargetURL.IsAbs() {
-			links[index].TargetURL = *currentURL.ResolveReference(&links[index].TargetURL)
+			links[index].TargetURL = *baseURL.ResolveReference(&links[index].TargetURL)
 		}
+
+		scheme := links[index].TargetURL.Scheme
+		links[index].IsExternal = (scheme == "http" || scheme == "https") &&
+			links[index].TargetURL.Host != currentURL.Host
 	}
-	return links, nil
 }
 
 func extractALinks(doc *goquery.Document) (links []Link) {
 	doc.Find("a").Each(func(i int, s *goquery.Selection) {
 		targetURL, _ := s.Attr("href")
 
-		// TODO: Check that # exists
-		if strings.HasPrefix(targetURL, "#") {
-			return
-		}
-
 		link := extractLink(targetURL)
 		if link == nil {
 			return
 		}
 
+		rel, _ := s.Attr("rel")
+		for _, relation := range strings.Fields(strings.ToLower(rel)) {
+			if relation == "nofollow" || relation == "sponsored" {
+				link.NoFollow = true
+			}
+		}
+
 		link.Type = Hyperlink
 		links = append(links, *link)
 	})
@@ -86,25 +191,239 @@ func extractALinks(doc *goquery.Document) (links []Link) {
 }
 
 func extractImageLinks(doc *goquery.Document) (links []Link) {
-	doc.Find("img").Each(func(i int, s *goquery.Selection) {
-		targetURL, _ := s.Attr("src")
+	links = extractAttributeLinks(doc, "img[src]", "src", Image)
+	links = append(links, extractSrcsetLinks(doc, "img[srcset], picture source[srcset]", Image)...)
+
+	return
+}
 
-		if strings.HasPrefix(targetURL, "data:") {
+// extractLinkTagLinks returns the links of 'link' tags, typed by their
+// relation. Relations which do not reference a resource, such as
+// 'preconnect', are ignored.
+func extractLinkTagLinks(doc *goquery.Document) (links []Link) {
+	doc.Find("link[href]").Each(func(i int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		linkType, ok := linkRelationType(rel)
+		if !ok {
 			return
 		}
 
-		link := extractLink(targetURL)
+		targetURL, _ := s.Attr("href")
+		link := extractResourceLink(targetURL)
+		if link == nil {
+			return
+		}
+
+		link.Type = linkType
+		if linkType == Preload {
+			destination, _ := s.Attr("as")
+			link.Destination = strings.ToLower(strings.TrimSpace(destination))
+		}
+		links = append(links, *link)
+	})
+
+	return
+}
+
+// linkRelationType returns the type of link of a 'link' tag from its
+// space-separated 'rel' attribute
+func linkRelationType(rel string) (LinkType, bool) {
+	relations := make(map[string]bool)
+	for _, relation := range strings.Fields(strings.ToLower(rel)) {
+		relations[relation] = true
+	}
+
+	switch {
+	case relations["stylesheet"]:
+		return Stylesheet, true
+	case relations["icon"] || relations["apple-touch-icon"] || relations["mask-icon"]:
+		return Icon, true
+	case relations["preload"] || relations["prefetch"] || relations["modulepreload"]:
+		return Preload, true
+	case relations["canonical"]:
+		return Canonical, true
+	case relations["alternate"]:
+		return Alternate, true
+	}
+
+	return 0, false
+}
+
+func extractMediaLinks(doc *goquery.Document) (links []Link) {
+	links = extractAttributeLinks(doc, "video[src], audio[src], video source[src], audio source[src]", "src",
+		Media)
+	links = append(links, extractAttributeLinks(doc, "video[poster]", "poster", Media)...)
+
+	return
+}
+
+// extractFormLinks returns the action of forms submitted with the GET method,
+// the only ones which can be crawled without side effects
+func extractFormLinks(doc *goquery.Document) (links []Link) {
+	doc.Find("form[action]").Each(func(i int, s *goquery.Selection) {
+		method, _ := s.Attr("method")
+		if method != "" && !strings.EqualFold(method, "get") {
+			return
+		}
+
+		targetURL, _ := s.Attr("action")
+		link := extractResourceLink(targetURL)
+		if link == nil {
+			return
+		}
+
+		link.Type = Form
+		links = append(links, *link)
+	})
+
+	return
+}
+
+func extractRefreshLinks(doc *goquery.Document) (links []Link) {
+	doc.Find("meta[http-equiv][content]").Each(func(i int, s *goquery.Selection) {
+		httpEquiv, _ := s.Attr("http-equiv")
+		if !strings.EqualFold(httpEquiv, "refresh") {
+			return
+		}
+
+		content, _ := s.Attr("content")
+		targetURL, ok := parseRefreshURL(content)
+		if !ok {
+			return
+		}
+
+		link := extractResourceLink(targetURL)
 		if link == nil {
 			return
 		}
 
-		link.Type = Image
+		link.Type = Refresh
 		links = append(links, *link)
 	})
 
 	return
 }
 
+// parseRefreshURL returns the URL of a meta refresh content, such as
+// "5; url=/next"
+func parseRefreshURL(content string) (string, bool) {
+	separator := strings.IndexAny(content, ";,")
+	if separator < 0 {
+		return "", false
+	}
+
+	target := strings.TrimSpace(content[separator+1:])
+	if len(target) < 4 || !strings.EqualFold(target[:3], "url") {
+		return "", false
+	}
+	target = strings.TrimSpace(target[3:])
+	if !strings.HasPrefix(target, "=") {
+		return "", false
+	}
+	target = strings.TrimSpace(target[1:])
+	target = strings.Trim(target, `"'`)
+
+	return target, target != ""
+}
+
+// extractAttributeLinks returns the links held by the attribute of the
+// elements matching the selector passed
+func extractAttributeLinks(doc *goquery.Document, selector string, attribute string,
+	linkType LinkType) (links []Link) {
+	doc.Find(selector).Each(func(i int, s *goquery.Selection) {
+		targetURL, _ := s.Attr(attribute)
+
+		link := extractResourceLink(targetURL)
+		if link == nil {
+			return
+		}
+
+		link.Type = linkType
+		links = append(links, *link)
+	})
+
+	return
+}
+
+// extractSrcsetLinks returns the links of each image candidate of the
+// 'srcset' attribute of the elements matching the selector passed
+func extractSrcsetLinks(doc *goquery.Document, selector string, linkType LinkType) (links []Link) {
+	doc.Find(selector).Each(func(i int, s *goquery.Selection) {
+		srcset, _ := s.Attr("srcset")
+
+		for _, targetURL := range parseSrcset(srcset) {
+			link := extractResourceLink(targetURL)
+			if link == nil {
+				continue
+			}
+
+			link.Type = linkType
+			links = append(links, *link)
+		}
+	})
+
+	return
+}
+
+// parseSrcset returns the URLs of the image candidates of a 'srcset'
+// attribute, such as "small.jpg 480w, large.jpg 1080w". Candidates are
+// separated by commas, which URLs may also contain.
+func parseSrcset(srcset string) (urls []string) {
+	isSpace := func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+	}
+
+	remaining := srcset
+	for {
+		remaining = strings.TrimLeftFunc(remaining, func(r rune) bool { return isSpace(r) || r == ',' })
+		if remaining == "" {
+			return
+		}
+
+		end := strings.IndexFunc(remaining, isSpace)
+		if end < 0 {
+			end = len(remaining)
+		}
+		candidateURL := remaining[:end]
+		remaining = remaining[end:]
+
+		if strings.HasSuffix(candidateURL, ",") {
+			urls = append(urls, strings.TrimRight(candidateURL, ","))
+			continue
+		}
+		urls = append(urls, candidateURL)
+
+		// Skip descriptors, up to the comma ending the candidate
+		depth := 0
+		end = strings.IndexFunc(remaining, func(r rune) bool {
+			switch r {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			case ',':
+				return depth <= 0
+			}
+			return false
+		})
+		if end < 0 {
+			return
+		}
+		remaining = remaining[end+1:]
+	}
+}
+
+// extractResourceLink returns the link to a resource, or nil if it is empty
+// or embeds its data
+func extractResourceLink(urlString string) *Link {
+	urlString = strings.TrimSpace(urlString)
+	if urlString == "" || strings.HasPrefix(urlString, "data:") {
+		return nil
+	}
+
+	return extractLink(urlString)
+}
+
 func extractLink(urlString string) *Link {
 	url, err := url.Parse(urlString)
 	if err != nil {
@@ -2,7 +2,11 @@ package crawler
 
 import (
 	"net/url"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
 func TestRewriteURLHost(t *testing.T) {
@@ -56,3 +60,143 @@ func TestRewriteURLHost(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractDocumentLinks(t *testing.T) {
+	page := `<html><head>
+		<link rel="stylesheet" href="/main.css">
+		<link rel="alternate stylesheet" href="/contrast.css">
+		<link rel="shortcut icon" href="/favicon.ico">
+		<link rel="preload" href="/font.woff2" as="font">
+		<link rel="preconnect" href="https://cdn.foo.bar">
+		<link rel="canonical" href="https://foo.bar/page">
+		<link rel="alternate" hreflang="fr" href="/fr/page">
+		<meta http-equiv="Refresh" content="5; URL='/next'">
+		<script src="/app.js"></script>
+		<script>inline()</script>
+	</head><body>
+		<a href="/about">About</a>
+		<img src="/logo.png" srcset="/logo-2x.png 2x, /logo,3x.png 3x">
+		<img src="data:image/png;base64,AAAA">
+		<picture><source srcset="/hero.webp 1x"><img src="/hero.jpg"></picture>
+		<iframe src="https://video.foo.bar/embed"></iframe>
+		<video src="/clip.mp4" poster="/clip.jpg"><source src="/clip.webm"></video>
+		<audio><source src="/sound.ogg"></audio>
+		<object data="/doc.pdf"></object>
+		<form action="/search"></form>
+		<form action="/login" method="post"></form>
+	</body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentURL, _ := url.Parse("https://foo.bar/page")
+
+	found := make(map[string]LinkType)
+	for _, link := range ExtractDocumentLinks(doc, *currentURL) {
+		found[link.TargetURL.String()] = link.Type
+	}
+
+	expected := map[string]LinkType{
+		"https://foo.bar/main.css":     Stylesheet,
+		"https://foo.bar/contrast.css": Stylesheet,
+		"https://foo.bar/favicon.ico":  Icon,
+		"https://foo.bar/font.woff2":   Preload,
+		"https://foo.bar/page":         Canonical,
+		"https://foo.bar/fr/page":      Alternate,
+		"https://foo.bar/next":         Refresh,
+		"https://foo.bar/app.js":       Script,
+		"https://foo.bar/about":        Hyperlink,
+		"https://foo.bar/logo.png":     Image,
+		"https://foo.bar/logo-2x.png":  Image,
+		"https://foo.bar/logo,3x.png":  Image,
+		"https://foo.bar/hero.webp":    Image,
+		"https://foo.bar/hero.jpg":     Image,
+		"https://video.foo.bar/embed":  Frame,
+		"https://foo.bar/clip.mp4":     Media,
+		"https://foo.bar/clip.jpg":     Media,
+		"https://foo.bar/clip.webm":    Media,
+		"https://foo.bar/sound.ogg":    Media,
+		"https://foo.bar/doc.pdf":      Object,
+		"https://foo.bar/search":       Form,
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected links %v, got %v", expected, found)
+	}
+}
+
+func TestParseSrcset(t *testing.T) {
+	tests := map[string][]string{
+		"":                                  nil,
+		"image.jpg":                         {"image.jpg"},
+		"small.jpg 480w, large.jpg 1080w":   {"small.jpg", "large.jpg"},
+		"a.jpg 1x,b.jpg 2x":                 {"a.jpg", "b.jpg"},
+		"a.jpg,b.jpg":                       {"a.jpg,b.jpg"},
+		"a.jpg, b.jpg 2x":                   {"a.jpg", "b.jpg"},
+		" image,1.jpg 1x , image,2.jpg 2x ": {"image,1.jpg", "image,2.jpg"},
+	}
+
+	for srcset, expected := range tests {
+		if urls := parseSrcset(srcset); !reflect.DeepEqual(urls, expected) {
+			t.Errorf("expected srcset '%s' to contain %v, got %v", srcset, expected, urls)
+		}
+	}
+}
+
+func TestParseRefreshURL(t *testing.T) {
+	tests := map[string]string{
+		"5; url=/next":       "/next",
+		"0;URL='/next'":      "/next",
+		"0, url = \"/next\"": "/next",
+		"5":                  "",
+		"5; /next":           "",
+	}
+
+	for content, expected := range tests {
+		if target, _ := parseRefreshURL(content); target != expected {
+			t.Errorf("expected refresh '%s' to target '%s', got '%s'", content, expected, target)
+		}
+	}
+}
+
+func TestExtractDocumentLinksBase(t *testing.T) {
+	page := `<html><head><base href="https://cdn.foo.bar/docs/"></head><body>
+		<a href="guide">Guide</a>
+		<a href="/about" rel="nofollow">About</a>
+		<a href="https://partner.bar/" rel="Sponsored noopener">Partner</a>
+		<a href="https://foo.bar/contact">Contact</a>
+		<a href="mailto:sales@foo.bar">Mail</a>
+	</body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentURL, _ := url.Parse("https://foo.bar/page")
+
+	expected := []Link{
+		{TargetURL: url.URL{Scheme: "https", Host: "cdn.foo.bar", Path: "/docs/guide"}, IsExternal: true},
+		{TargetURL: url.URL{Scheme: "https", Host: "cdn.foo.bar", Path: "/about"}, IsExternal: true, NoFollow: true},
+		{TargetURL: url.URL{Scheme: "https", Host: "partner.bar", Path: "/"}, IsExternal: true, NoFollow: true},
+		{TargetURL: url.URL{Scheme: "https", Host: "foo.bar", Path: "/contact"}},
+		{TargetURL: url.URL{Scheme: "mailto", Opaque: "sales@foo.bar"}},
+	}
+
+	links := ExtractDocumentLinks(doc, *currentURL)
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected links %+v, got %+v", expected, links)
+	}
+}
+
+func TestCrawlPageLinksConfigAllowsScheme(t *testing.T) {
+	var config CrawlPageLinksConfig
+	if !config.allowsScheme("https") || !config.allowsScheme("HTTP") || config.allowsScheme("mailto") ||
+		config.allowsScheme("javascript") {
+		t.Errorf("expected only http and https links to be allowed by default")
+	}
+
+	config.Schemes = []string{"https"}
+	if !config.allowsScheme("https") || config.allowsScheme("http") {
+		t.Errorf("expected allowed schemes to replace the default ones")
+	}
+}
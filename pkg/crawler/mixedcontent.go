@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"mime"
+	"path"
+	"strings"
+)
+
+// Classes of mixed content. Browsers block active mixed content, and may
+// block or upgrade passive mixed content.
+const (
+	ActiveMixedContent  = "active"
+	PassiveMixedContent = "passive"
+)
+
+// mixedContentClass returns whether the subresource linked is active or
+// passive mixed content when loaded over http from an https page.
+// Navigations, such as hyperlinks, are not subresources. Preloaded resources
+// and stylesheet resources are classified by their destination or media type.
+func mixedContentClass(link Link) (string, bool) {
+	switch link.Type {
+	case Script, Stylesheet, Frame, Object:
+		return ActiveMixedContent, true
+	case Image, Icon, Media:
+		return PassiveMixedContent, true
+	case Preload:
+		if isPassiveDestination(link.Destination) {
+			return PassiveMixedContent, true
+		}
+		return ActiveMixedContent, true
+	case StylesheetResource:
+		mediaType := mime.TypeByExtension(path.Ext(link.TargetURL.Path))
+		if isPassiveDestination(strings.SplitN(mediaType, "/", 2)[0]) {
+			return PassiveMixedContent, true
+		}
+		return ActiveMixedContent, true
+	}
+
+	return "", false
+}
+
+// isPassiveDestination returns whether resources of the destination passed,
+// such as "image", are passive mixed content
+func isPassiveDestination(destination string) bool {
+	return destination == "image" || destination == "audio" || destination == "video"
+}
+
+// findMixedContent returns the subresources of an https page which are
+// loaded over http, such as "active script http://foo.bar/app.js"
+func findMixedContent(links []Link) (issues []string) {
+	for _, link := range links {
+		if link.TargetURL.Scheme != "http" {
+			continue
+		}
+
+		class, ok := mixedContentClass(link)
+		if !ok {
+			continue
+		}
+
+		issues = appendUnique(issues, class+" "+link.Type.String()+" "+link.TargetURL.String())
+	}
+
+	return
+}
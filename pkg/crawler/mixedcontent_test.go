@@ -0,0 +1,109 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPGetMixedContent(t *testing.T) {
+	page := `<html><head>
+		<link rel="stylesheet" href="http://cdn.foo.bar/main.css">
+		<script src="http://cdn.foo.bar/app.js"></script>
+		<script src="http://cdn.foo.bar/app.js"></script>
+		<script src="/secure.js"></script>
+		<link rel="preload" href="http://cdn.foo.bar/hero.jpg" as="image">
+		<link rel="preload" href="http://cdn.foo.bar/vendor.js" as="script">
+	</head><body>
+		<a href="http://foo.bar/about">About</a>
+		<img src="http://cdn.foo.bar/logo.png">
+		<iframe src="http://video.foo.bar/embed"></iframe>
+		<video poster="http://cdn.foo.bar/clip.jpg"></video>
+		<form action="http://foo.bar/search"></form>
+	</body></html>`
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, page)
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	config := HTTPConfig{ParseLinks: true, CheckMixedContent: true}
+	response := HTTPGet(server.Client(), server.URL, config)
+
+	expected := []string{
+		"active stylesheet http://cdn.foo.bar/main.css",
+		"active script http://cdn.foo.bar/app.js",
+		"passive image http://cdn.foo.bar/logo.png",
+		"active iframe http://video.foo.bar/embed",
+		"passive media http://cdn.foo.bar/clip.jpg",
+		"passive preload http://cdn.foo.bar/hero.jpg",
+		"active preload http://cdn.foo.bar/vendor.js",
+	}
+	found := make(map[string]bool)
+	for _, issue := range response.MixedContent {
+		found[issue] = true
+	}
+	if len(response.MixedContent) != len(expected) {
+		t.Errorf("expected %d mixed content resources, got %v", len(expected), response.MixedContent)
+	}
+	for _, issue := range expected {
+		if !found[issue] {
+			t.Errorf("expected '%s' to be reported, got %v", issue, response.MixedContent)
+		}
+	}
+
+	config.ParseLinks = false
+	response = HTTPGet(server.Client(), server.URL, config)
+	if len(response.MixedContent) != len(expected) || len(response.Links) > 0 {
+		t.Errorf("expected pages to be checked without keeping their links, got %v and %v",
+			response.MixedContent, response.Links)
+	}
+
+	insecureServer := httptest.NewServer(handler)
+	defer insecureServer.Close()
+
+	response = HTTPGet(insecureServer.Client(), insecureServer.URL, config)
+	if len(response.MixedContent) > 0 {
+		t.Errorf("expected http pages not to be checked, got %v", response.MixedContent)
+	}
+}
+
+func TestHTTPGetStylesheetMixedContent(t *testing.T) {
+	stylesheet := `@import url("http://cdn.foo.bar/theme.css");
+		body { background: url(http://cdn.foo.bar/background.png); }
+		@font-face { src: url("http://cdn.foo.bar/font.woff2"); }
+		h1 { background: url(/secure.png); }`
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		fmt.Fprint(w, stylesheet)
+	}))
+	defer server.Close()
+
+	response := HTTPGet(server.Client(), server.URL+"/main.css", HTTPConfig{CheckMixedContent: true})
+
+	expected := []string{
+		"active stylesheet http://cdn.foo.bar/theme.css",
+		"passive stylesheet-resource http://cdn.foo.bar/background.png",
+		"active stylesheet-resource http://cdn.foo.bar/font.woff2",
+	}
+	if len(response.MixedContent) != len(expected) {
+		t.Fatalf("expected %d mixed content resources, got %v", len(expected), response.MixedContent)
+	}
+	for i, issue := range expected {
+		if response.MixedContent[i] != issue {
+			t.Errorf("expected '%s' to be reported, got '%s'", issue, response.MixedContent[i])
+		}
+	}
+}
+
+func TestMergeMixedContent(t *testing.T) {
+	issue := CrawlResult{URL: "https://foo.bar/", Issues: []string{"http://foo.bar/logo.png"}}
+	stats := CrawlStats{MixedContent: []CrawlResult{issue}}
+	if merged := MergeCrawlStats(stats, stats); len(merged.MixedContent) != 1 {
+		t.Errorf("expected mixed content to be listed once across iterations, got %+v", merged.MixedContent)
+	}
+}
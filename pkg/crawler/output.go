@@ -2,45 +2,270 @@ package crawler
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 type summary struct {
-	General          generalInfo      `json:"total"`
-	StatusInfo       statusInfo       `json:"status"`
-	ResponseTimeInfo responseTimeInfo `json:"response-time"`
+	General          generalInfo              `json:"total"`
+	StatusInfo       statusInfo               `json:"status"`
+	ResponseTimeInfo responseTimeInfo         `json:"response-time"`
+	Certificates     []CertificateInfo        `json:"certificates,omitempty"`
+	SecurityIssues   []CrawlResult            `json:"security-issues,omitempty"`
+	Revalidation     []CrawlResult            `json:"revalidation-issues,omitempty"`
+	MixedContent     []CrawlResult            `json:"mixed-content,omitempty"`
+	BrokenAnchors    []CrawlResult            `json:"broken-anchors,omitempty"`
+	ContactLinks     []CrawlResult            `json:"contact-link-issues,omitempty"`
+	Canonicals       []CrawlResult            `json:"canonical-issues,omitempty"`
+	SEO              map[string][]CrawlResult `json:"seo-issues,omitempty"`
+	Coverage         *coverageInfo            `json:"coverage,omitempty"`
+	Graph            *graphInfo               `json:"graph,omitempty"`
+	CacheInfo        *cacheInfo               `json:"cache,omitempty"`
+	Variants         map[string]variantInfo   `json:"variants,omitempty"`
+	Compression      *compressionInfo         `json:"compression,omitempty"`
+	Phases           map[string]phaseInfo     `json:"phases,omitempty"`
+	Groups           map[string]groupInfo     `json:"groups,omitempty"`
+	Policy           []RuleResult             `json:"policy,omitempty"`
+}
+
+type graphInfo struct {
+	Nodes       int         `json:"nodes"`
+	Edges       int         `json:"edges"`
+	MostLinked  []GraphNode `json:"most-linked"`
+	MostLinking []GraphNode `json:"most-linking"`
+}
+
+// graphTopNodes is the number of nodes with the highest degrees listed in
+// the summary
+const graphTopNodes = 5
+
+func newGraphInfo(graph *LinkGraph) *graphInfo {
+	nodes := graph.Nodes()
+	return &graphInfo{
+		Nodes:       len(nodes),
+		Edges:       len(graph.Edges),
+		MostLinked:  topNodes(nodes, graphTopNodes, func(node GraphNode) int { return node.InDegree }),
+		MostLinking: topNodes(nodes, graphTopNodes, func(node GraphNode) int { return node.OutDegree }),
+	}
+}
+
+type coverageInfo struct {
+	Orphans      []CrawlResult `json:"orphans"`
+	NotInSitemap []CrawlResult `json:"not-in-sitemap"`
+}
+
+type groupInfo struct {
+	Total         int           `json:"crawled"`
+	StatusCodes   map[int]int   `json:"status-codes"`
+	Unexpected    []CrawlResult `json:"errors"`
+	AverageTimeMs int           `json:"avg-time-ms"`
+	P50TimeMs     int           `json:"p50-time-ms"`
+	P90TimeMs     int           `json:"p90-time-ms"`
+	P95TimeMs     int           `json:"p95-time-ms"`
+	P99TimeMs     int           `json:"p99-time-ms"`
+	MaxTimeMs     int           `json:"max-time-ms"`
+}
+
+type phaseInfo struct {
+	Count         int64 `json:"count"`
+	AverageTimeMs int   `json:"avg-time-ms"`
+	P50TimeMs     int   `json:"p50-time-ms"`
+	P90TimeMs     int   `json:"p90-time-ms"`
+	P95TimeMs     int   `json:"p95-time-ms"`
+	P99TimeMs     int   `json:"p99-time-ms"`
+	MaxTimeMs     int   `json:"max-time-ms"`
+}
+
+type compressionInfo struct {
+	ContentTypes map[string]contentTypeCompressionInfo `json:"content-types"`
+	Issues       []CrawlResult                         `json:"issues,omitempty"`
+}
+
+type contentTypeCompressionInfo struct {
+	CompressionStats
+	Ratio float64 `json:"ratio"`
+}
+
+type variantInfo struct {
+	Total         int         `json:"crawled"`
+	StatusCodes   map[int]int `json:"status-codes"`
+	Unexpected    int         `json:"unexpected"`
+	AverageTimeMs int         `json:"avg-time-ms"`
+	MaxTimeMs     int         `json:"max-time-ms"`
+}
+
+type cacheInfo struct {
+	Iterations  []cacheIterationInfo `json:"iterations"`
+	Uncacheable []CrawlResult        `json:"uncacheable"`
+	Headers     map[string]CacheInfo `json:"headers,omitempty"`
+}
+
+type cacheIterationInfo struct {
+	CacheStats
+	HitRatio float64 `json:"hit-ratio"`
 }
 
 type generalInfo struct {
-	Total int `json:"crawled"`
+	Total      int   `json:"crawled"`
+	Discovered int   `json:"discovered,omitempty"`
+	BodySize   int64 `json:"body-size"`
 }
 
 type statusInfo struct {
-	StatusCodes map[int]int   `json:"status-codes"`
-	Non200Urls  []CrawlResult `json:"errors"`
+	StatusCodes       map[int]int   `json:"status-codes"`
+	Expected          int           `json:"expected"`
+	Unexpected        int           `json:"unexpected"`
+	Non200Urls        []CrawlResult `json:"errors"`
+	UnexpectedUrls    []CrawlResult `json:"unexpected-errors,omitempty"`
+	AssertionFailures []CrawlResult `json:"assertion-errors,omitempty"`
+	Soft404Urls       []CrawlResult `json:"soft-404,omitempty"`
 }
 
 type responseTimeInfo struct {
-	AverageTimeMs int `json:"avg-time-ms"`
-	MaxTimeMs     int `json:"max-time-ms"`
+	AverageTimeMs int             `json:"avg-time-ms"`
+	MaxTimeMs     int             `json:"max-time-ms"`
+	P50TimeMs     int             `json:"p50-time-ms"`
+	P90TimeMs     int             `json:"p90-time-ms"`
+	P95TimeMs     int             `json:"p95-time-ms"`
+	P99TimeMs     int             `json:"p99-time-ms"`
+	Histogram     []histogramInfo `json:"histogram,omitempty"`
+}
+
+type histogramInfo struct {
+	LowerMs int   `json:"lower-ms"`
+	UpperMs int   `json:"upper-ms"`
+	Count   int64 `json:"count"`
 }
 
 // PrintJSONSummary prints a summary of HTTP response codes in JSON format
 func PrintJSONSummary(stats CrawlStats) {
 	summary := summary{
 		General: generalInfo{
-			Total: stats.Total,
+			Total:      stats.Total,
+			Discovered: stats.Discovered,
+			BodySize:   stats.TotalBodySize,
 		},
 		StatusInfo: statusInfo{
-			StatusCodes: stats.StatusCodes,
-			Non200Urls:  stats.Non200Urls,
+			StatusCodes:       stats.StatusCodes,
+			Expected:          stats.ExpectedStatuses,
+			Unexpected:        len(stats.UnexpectedStatuses),
+			Non200Urls:        stats.Non200Urls,
+			UnexpectedUrls:    stats.UnexpectedStatuses,
+			AssertionFailures: stats.AssertionFailures,
+			Soft404Urls:       stats.Soft404Urls,
 		},
 		ResponseTimeInfo: responseTimeInfo{
 			AverageTimeMs: int(stats.Average200Time / time.Millisecond),
 			MaxTimeMs:     int(stats.Max200Time / time.Millisecond),
-		}}
+			P50TimeMs:     int(stats.ResponseTimes.Percentile(50) / time.Millisecond),
+			P90TimeMs:     int(stats.ResponseTimes.Percentile(90) / time.Millisecond),
+			P95TimeMs:     int(stats.ResponseTimes.Percentile(95) / time.Millisecond),
+			P99TimeMs:     int(stats.ResponseTimes.Percentile(99) / time.Millisecond),
+		},
+		Certificates:   sortedCertificates(stats.Certificates),
+		SecurityIssues: stats.SecurityIssues,
+		Revalidation:   stats.RevalidationIssues,
+		MixedContent:   stats.MixedContent,
+		BrokenAnchors:  stats.BrokenAnchors,
+		ContactLinks:   stats.ContactLinkIssues,
+		Canonicals:     stats.CanonicalIssues,
+		SEO:            stats.SEOIssues,
+		Policy:         stats.PolicyResults,
+	}
+
+	if len(stats.Variants) > 0 {
+		summary.Variants = make(map[string]variantInfo)
+		for name, variantStats := range stats.Variants {
+			summary.Variants[name] = variantInfo{
+				Total:         variantStats.Total,
+				StatusCodes:   variantStats.StatusCodes,
+				Unexpected:    variantStats.Unexpected,
+				AverageTimeMs: int(variantStats.Average200Time / time.Millisecond),
+				MaxTimeMs:     int(variantStats.Max200Time / time.Millisecond),
+			}
+		}
+	}
+
+	if len(stats.CacheIterations) > 0 {
+		summary.CacheInfo = &cacheInfo{Uncacheable: stats.UncacheableUrls, Headers: stats.CacheHeaders}
+		for _, iteration := range stats.CacheIterations {
+			summary.CacheInfo.Iterations = append(summary.CacheInfo.Iterations, cacheIterationInfo{
+				CacheStats: iteration,
+				HitRatio:   iteration.HitRatio(),
+			})
+		}
+	}
+
+	if len(stats.Orphans) > 0 || len(stats.NotInSitemap) > 0 {
+		summary.Coverage = &coverageInfo{
+			Orphans:      stats.Orphans,
+			NotInSitemap: stats.NotInSitemap,
+		}
+	}
+
+	if stats.Graph != nil {
+		summary.Graph = newGraphInfo(stats.Graph)
+	}
+
+	if len(stats.Compression) > 0 {
+		summary.Compression = &compressionInfo{
+			ContentTypes: make(map[string]contentTypeCompressionInfo),
+			Issues:       stats.CompressionIssues,
+		}
+		for contentType, compressionStats := range stats.Compression {
+			summary.Compression.ContentTypes[contentType] = contentTypeCompressionInfo{
+				CompressionStats: compressionStats,
+				Ratio:            compressionStats.Ratio(),
+			}
+		}
+	}
+
+	if len(stats.PhaseTimes) > 0 {
+		summary.Phases = make(map[string]phaseInfo)
+		for phase, histogram := range stats.PhaseTimes {
+			summary.Phases[phase] = phaseInfo{
+				Count:         histogram.Count,
+				AverageTimeMs: int(histogram.Mean() / time.Millisecond),
+				P50TimeMs:     int(histogram.Percentile(50) / time.Millisecond),
+				P90TimeMs:     int(histogram.Percentile(90) / time.Millisecond),
+				P95TimeMs:     int(histogram.Percentile(95) / time.Millisecond),
+				P99TimeMs:     int(histogram.Percentile(99) / time.Millisecond),
+				MaxTimeMs:     int(histogram.Max / time.Millisecond),
+			}
+		}
+	}
+
+	if len(stats.Groups) > 0 {
+		summary.Groups = make(map[string]groupInfo)
+		for name, groupStats := range stats.Groups {
+			histogram := groupStats.ResponseTimes
+			summary.Groups[name] = groupInfo{
+				Total:         groupStats.Total,
+				StatusCodes:   groupStats.StatusCodes,
+				Unexpected:    groupStats.Unexpected,
+				AverageTimeMs: int(histogram.Mean() / time.Millisecond),
+				P50TimeMs:     int(histogram.Percentile(50) / time.Millisecond),
+				P90TimeMs:     int(histogram.Percentile(90) / time.Millisecond),
+				P95TimeMs:     int(histogram.Percentile(95) / time.Millisecond),
+				P99TimeMs:     int(histogram.Percentile(99) / time.Millisecond),
+				MaxTimeMs:     int(histogram.Max / time.Millisecond),
+			}
+		}
+	}
+
+	for _, bucket := range stats.ResponseTimes.Buckets() {
+		summary.ResponseTimeInfo.Histogram = append(summary.ResponseTimeInfo.Histogram, histogramInfo{
+			LowerMs: int(bucket.Lower / time.Millisecond),
+			UpperMs: int(bucket.Upper / time.Millisecond),
+			Count:   bucket.Count,
+		})
+	}
 
 	jsonSummary, err := json.Marshal(summary)
 	if err != nil {
@@ -56,11 +281,17 @@ func PrintSummary(stats CrawlStats) {
 	log.Info("-------- Summary -------")
 	log.Info("general:")
 	log.Info("    crawled: ", stats.Total)
+	if stats.Discovered > 0 {
+		log.Info("    discovered: ", stats.Discovered)
+	}
+	log.Info("    body-size: ", stats.TotalBodySize, " bytes")
 	log.Info("")
 	log.Info("status:")
 	for code, count := range stats.StatusCodes {
 		log.Info("    status-", code, ": ", count)
 	}
+	log.Info("    expected: ", stats.ExpectedStatuses)
+	log.Info("    unexpected: ", len(stats.UnexpectedStatuses))
 
 	log.Info("")
 	log.Info("status-errors-detail:")
@@ -76,9 +307,408 @@ func PrintSummary(stats CrawlStats) {
 		}
 	}
 
+	// Unexpected statuses only differ from non-200 ones when accepted or
+	// expected statuses are configured
+	if !sameResultURLs(stats.UnexpectedStatuses, stats.Non200Urls) {
+		log.Info("")
+		log.Info("unexpected-status-detail:")
+		if len(stats.UnexpectedStatuses) == 0 {
+			log.Info("    - none")
+		}
+		for _, crawlResult := range stats.UnexpectedStatuses {
+			log.Info("    - ", crawlResult.URL, ":")
+			log.Info("        status-code: ", crawlResult.StatusCode)
+			for _, linkingURL := range crawlResult.LinkingURLs {
+				log.Info("        linking-url: ", linkingURL)
+			}
+		}
+	}
+
+	if len(stats.AssertionFailures) > 0 {
+		log.Info("")
+		log.Info("assertion-errors-detail:")
+		for _, crawlResult := range stats.AssertionFailures {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        failure: ", issue)
+			}
+			for _, linkingURL := range crawlResult.LinkingURLs {
+				log.Info("        linking-url: ", linkingURL)
+			}
+		}
+	}
+
+	if len(stats.Soft404Urls) > 0 {
+		log.Info("")
+		log.Info("soft-404-detail:")
+		for _, crawlResult := range stats.Soft404Urls {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        reason: ", issue)
+			}
+		}
+	}
+
+	if len(stats.SecurityIssues) > 0 {
+		log.Info("")
+		log.Info("security-issues-detail:")
+		for _, crawlResult := range stats.SecurityIssues {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        issue: ", issue)
+			}
+		}
+	}
+
+	if len(stats.RevalidationIssues) > 0 {
+		log.Info("")
+		log.Info("revalidation-issues-detail:")
+		for _, crawlResult := range stats.RevalidationIssues {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        issue: ", issue)
+			}
+		}
+	}
+
+	if len(stats.MixedContent) > 0 {
+		log.Info("")
+		log.Info("mixed-content-detail:")
+		for _, crawlResult := range stats.MixedContent {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        resource: ", issue)
+			}
+		}
+	}
+
+	if len(stats.BrokenAnchors) > 0 {
+		log.Info("")
+		log.Info("broken-anchors-detail:")
+		for _, crawlResult := range stats.BrokenAnchors {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        issue: ", issue)
+			}
+			for _, linkingURL := range crawlResult.LinkingURLs {
+				log.Info("        linking-url: ", linkingURL)
+			}
+		}
+	}
+
+	if len(stats.ContactLinkIssues) > 0 {
+		log.Info("")
+		log.Info("contact-link-issues-detail:")
+		for _, crawlResult := range stats.ContactLinkIssues {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        issue: ", issue)
+			}
+			for _, linkingURL := range crawlResult.LinkingURLs {
+				log.Info("        linking-url: ", linkingURL)
+			}
+		}
+	}
+
+	if len(stats.CanonicalIssues) > 0 {
+		log.Info("")
+		log.Info("canonical-issues-detail:")
+		for _, crawlResult := range stats.CanonicalIssues {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        issue: ", issue)
+			}
+		}
+	}
+
+	if stats.SEOIssueCount() > 0 {
+		log.Info("")
+		log.Info("seo-issues-detail:")
+		for _, issueType := range SEOIssueTypes {
+			results := stats.SEOIssues[issueType]
+			if len(results) == 0 {
+				continue
+			}
+			log.Info("    - ", issueType, ": ", len(results), " page(s)")
+			for _, crawlResult := range results {
+				log.Info("        - ", crawlResult.URL)
+				for _, issue := range crawlResult.Issues {
+					log.Info("            issue: ", issue)
+				}
+			}
+		}
+	}
+
+	if len(stats.Orphans) > 0 {
+		log.Info("")
+		log.Info("orphans-detail:")
+		for _, crawlResult := range stats.Orphans {
+			log.Info("    - ", crawlResult.URL)
+		}
+	}
+
+	if len(stats.NotInSitemap) > 0 {
+		log.Info("")
+		log.Info("not-in-sitemap-detail:")
+		for _, crawlResult := range stats.NotInSitemap {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, linkingURL := range crawlResult.LinkingURLs {
+				log.Info("        linking-url: ", linkingURL)
+			}
+		}
+	}
+
+	if stats.Graph != nil {
+		info := newGraphInfo(stats.Graph)
+		log.Info("")
+		log.Info("graph:")
+		log.Info("    nodes: ", info.Nodes)
+		log.Info("    edges: ", info.Edges)
+		log.Info("    most-linked:")
+		for _, node := range info.MostLinked {
+			log.Info("        - ", node.URL, ": ", node.InDegree, " linking page(s)")
+		}
+		log.Info("    most-linking:")
+		for _, node := range info.MostLinking {
+			log.Info("        - ", node.URL, ": ", node.OutDegree, " linked URL(s)")
+		}
+	}
+
 	log.Info("")
 	log.Info("server-time: ")
 	log.Info("    avg-time: ", int(stats.Average200Time/time.Millisecond), "ms")
 	log.Info("    max-time: ", int(stats.Max200Time/time.Millisecond), "ms")
+	for _, percentile := range []int{50, 90, 95, 99} {
+		log.Info("    p", percentile, "-time: ",
+			int(stats.ResponseTimes.Percentile(float64(percentile))/time.Millisecond), "ms")
+	}
+	printHistogram(stats.ResponseTimes)
+
+	if len(stats.PhaseTimes) > 0 {
+		log.Info("")
+		log.Info("phases:")
+		for _, phase := range Phases {
+			histogram, ok := stats.PhaseTimes[phase]
+			if !ok {
+				continue
+			}
+			log.Info("    - ", phase, ":")
+			log.Info("        requests: ", histogram.Count)
+			log.Info("        avg-time: ", int(histogram.Mean()/time.Millisecond), "ms")
+			for _, percentile := range []int{50, 90, 95, 99} {
+				log.Info("        p", percentile, "-time: ",
+					int(histogram.Percentile(float64(percentile))/time.Millisecond), "ms")
+			}
+			log.Info("        max-time: ", int(histogram.Max/time.Millisecond), "ms")
+		}
+	}
+
+	if len(stats.Groups) > 0 {
+		names := make([]string, 0, len(stats.Groups))
+		for name := range stats.Groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		log.Info("")
+		log.Info("groups:")
+		for _, name := range names {
+			groupStats := stats.Groups[name]
+			histogram := groupStats.ResponseTimes
+			log.Info("    - ", name, ":")
+			log.Info("        crawled: ", groupStats.Total)
+			for code, count := range groupStats.StatusCodes {
+				log.Info("        status-", code, ": ", count)
+			}
+			log.Info("        avg-time: ", int(histogram.Mean()/time.Millisecond), "ms")
+			for _, percentile := range []int{50, 90, 95, 99} {
+				log.Info("        p", percentile, "-time: ",
+					int(histogram.Percentile(float64(percentile))/time.Millisecond), "ms")
+			}
+			log.Info("        max-time: ", int(histogram.Max/time.Millisecond), "ms")
+			for _, crawlResult := range groupStats.Unexpected {
+				log.Info("        error: ", crawlResult.URL, " (status ", crawlResult.StatusCode, ")")
+			}
+		}
+	}
+
+	if len(stats.Variants) > 0 {
+		names := make([]string, 0, len(stats.Variants))
+		for name := range stats.Variants {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		log.Info("")
+		log.Info("variants:")
+		for _, name := range names {
+			variantStats := stats.Variants[name]
+			log.Info("    - ", name, ":")
+			log.Info("        crawled: ", variantStats.Total)
+			for code, count := range variantStats.StatusCodes {
+				log.Info("        status-", code, ": ", count)
+			}
+			log.Info("        unexpected: ", variantStats.Unexpected)
+			log.Info("        avg-time: ", int(variantStats.Average200Time/time.Millisecond), "ms")
+			log.Info("        max-time: ", int(variantStats.Max200Time/time.Millisecond), "ms")
+		}
+	}
+
+	if len(stats.CacheIterations) > 0 {
+		log.Info("")
+		log.Info("cache:")
+		for i, iteration := range stats.CacheIterations {
+			log.Info("    - iteration-", i+1, ":")
+			log.Info("        hit: ", iteration.Hits)
+			log.Info("        miss: ", iteration.Misses)
+			log.Info("        bypass: ", iteration.Bypasses)
+			log.Info("        unknown: ", iteration.Unknown)
+			log.Info("        hit-ratio: ", int(iteration.HitRatio()*100), "%")
+		}
+
+		log.Info("")
+		log.Info("uncacheable-detail:")
+		if len(stats.UncacheableUrls) == 0 {
+			log.Info("    - none")
+		}
+		for _, crawlResult := range stats.UncacheableUrls {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        reason: ", issue)
+			}
+		}
+
+		urls := make([]string, 0, len(stats.CacheHeaders))
+		for url := range stats.CacheHeaders {
+			urls = append(urls, url)
+		}
+		sort.Strings(urls)
+
+		log.Info("")
+		log.Info("cache-headers-detail:")
+		for _, url := range urls {
+			info := stats.CacheHeaders[url]
+			log.Info("    - ", url, ":")
+			for _, header := range [][2]string{
+				{"cache-control", info.CacheControl}, {"age", info.Age}, {"etag", info.ETag},
+				{"last-modified", info.LastModified}, {"vary", info.Vary}, {"cache-status", info.Status},
+			} {
+				if header[1] != "" {
+					log.Info("        ", header[0], ": ", header[1])
+				}
+			}
+			log.Info("        result: ", info.Result)
+		}
+	}
+
+	if len(stats.Compression) > 0 {
+		contentTypes := make([]string, 0, len(stats.Compression))
+		for contentType := range stats.Compression {
+			contentTypes = append(contentTypes, contentType)
+		}
+		sort.Strings(contentTypes)
+
+		log.Info("")
+		log.Info("compression:")
+		for _, contentType := range contentTypes {
+			compressionStats := stats.Compression[contentType]
+			log.Info("    - ", contentType, ":")
+			log.Info("        responses: ", compressionStats.Responses)
+			log.Info("        compressed: ", compressionStats.Compressed)
+			log.Info("        transfer-size: ", compressionStats.TransferSize, " bytes")
+			log.Info("        body-size: ", compressionStats.BodySize, " bytes")
+			log.Info("        ratio: ", int(compressionStats.Ratio()*100), "%")
+		}
+
+		log.Info("")
+		log.Info("compression-issues-detail:")
+		if len(stats.CompressionIssues) == 0 {
+			log.Info("    - none")
+		}
+		for _, crawlResult := range stats.CompressionIssues {
+			log.Info("    - ", crawlResult.URL, ":")
+			for _, issue := range crawlResult.Issues {
+				log.Info("        issue: ", issue)
+			}
+		}
+	}
+
+	if len(stats.Certificates) > 0 {
+		log.Info("")
+		log.Info("certificates:")
+		for _, info := range sortedCertificates(stats.Certificates) {
+			log.Info("    - ", info.Host, ":")
+			log.Info("        subject: ", info.Subject)
+			log.Info("        issuer: ", info.Issuer)
+			if !info.NotAfter.IsZero() {
+				log.Info("        expires: ", info.NotAfter.Format("2006-01-02"), " (", info.DaysLeft, " days)")
+			}
+			for _, issue := range info.Issues {
+				log.Info("        issue: ", issue)
+			}
+		}
+	}
+
+	if len(stats.PolicyResults) > 0 {
+		log.Info("")
+		log.Info("policy:")
+		for _, result := range stats.PolicyResults {
+			outcome := "pass"
+			if !result.Passed && result.Rule.Severity == SeverityWarning {
+				outcome = "warn"
+			} else if !result.Passed {
+				outcome = "FAIL"
+			}
+
+			value := "no value"
+			if result.HasValue {
+				value = strconv.FormatFloat(math.Round(result.Value*100)/100, 'f', -1, 64)
+			}
+			log.Info("    - ", outcome, " ", result.Rule.Name, ": ", value, " (expected ", result.Rule.String(), ")")
+		}
+	}
 	log.Info("------------------------")
 }
+
+// printHistogram prints the buckets of the histogram as horizontal bars
+func printHistogram(histogram Histogram) {
+	const barWidth = 40
+
+	buckets := histogram.Buckets()
+	if len(buckets) == 0 {
+		return
+	}
+
+	var maxCount int64
+	for _, bucket := range buckets {
+		if bucket.Count > maxCount {
+			maxCount = bucket.Count
+		}
+	}
+
+	log.Info("    histogram:")
+	for _, bucket := range buckets {
+		label := fmt.Sprintf("%d-%dms", bucket.Lower/time.Millisecond, bucket.Upper/time.Millisecond)
+		bar := strings.Repeat("#", int(bucket.Count*barWidth/maxCount))
+		log.Info(fmt.Sprintf("        %-14s |%-*s %d", label, barWidth, bar, bucket.Count))
+	}
+}
+
+// sameResultURLs returns whether both lists of results passed report the
+// same URLs
+func sameResultURLs(resultsA []CrawlResult, resultsB []CrawlResult) bool {
+	if len(resultsA) != len(resultsB) {
+		return false
+	}
+
+	urls := make(map[string]bool)
+	for _, result := range resultsA {
+		urls[result.URL] = true
+	}
+	for _, result := range resultsB {
+		if !urls[result.URL] {
+			return false
+		}
+	}
+	return true
+}
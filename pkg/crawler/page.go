@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/brotli"
+)
+
+// page holds the downloaded body of a response, and lazily parses it as an
+// HTML document so that it is parsed at most once per response.
+type page struct {
+	content []byte
+	doc     *goquery.Document
+	err     error
+	parsed  bool
+}
+
+func (p *page) document() (*goquery.Document, error) {
+	if !p.parsed {
+		p.doc, p.err = goquery.NewDocumentFromReader(bytes.NewReader(p.content))
+		p.parsed = true
+	}
+
+	return p.doc, p.err
+}
+
+// isHTML returns whether the response headers describe an HTML document
+func isHTML(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml")
+}
+
+// decodeContent decompresses content encoded with gzip, deflate or br. Truncated
+// content is decoded as far as possible.
+func decodeContent(content []byte, encoding string) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "identity":
+		return content, nil
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+	case "deflate":
+		// Deflate is meant to be zlib wrapped, although raw streams are common
+		reader, err = zlib.NewReader(bytes.NewReader(content))
+		if err != nil {
+			reader = flate.NewReader(bytes.NewReader(content))
+		}
+	case "br":
+		reader = io.NopCloser(brotli.NewReader(bytes.NewReader(content)))
+	default:
+		return nil, fmt.Errorf("unsupported content encoding '%s'", encoding)
+	}
+	defer reader.Close()
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,57 @@
+package crawler
+
+import "time"
+
+// Timing phases of a request, as measured by httpstat
+const (
+	PhaseDNS      = "dns"
+	PhaseTCP      = "tcp"
+	PhaseTLS      = "tls"
+	PhaseTTFB     = "ttfb"
+	PhaseTransfer = "transfer"
+)
+
+// Phases lists the timing phases of a request, in chronological order
+var Phases = []string{PhaseDNS, PhaseTCP, PhaseTLS, PhaseTTFB, PhaseTransfer}
+
+// phaseTimes returns the duration of each phase of the request. Connection
+// phases are omitted when they did not happen, such as on reused connections.
+func phaseTimes(result *HTTPResponse) map[string]time.Duration {
+	if result.Result == nil || result.Err != nil {
+		return nil
+	}
+
+	times := map[string]time.Duration{
+		PhaseTTFB: result.Result.ServerProcessing,
+	}
+	if result.Result.DNSLookup > 0 {
+		times[PhaseDNS] = result.Result.DNSLookup
+	}
+	if result.Result.TCPConnection > 0 {
+		times[PhaseTCP] = result.Result.TCPConnection
+	}
+	if result.Result.TLSHandshake > 0 {
+		times[PhaseTLS] = result.Result.TLSHandshake
+	}
+	if !result.BodyEndTime.IsZero() {
+		times[PhaseTransfer] = result.Result.ContentTransfer(result.BodyEndTime)
+	}
+
+	return times
+}
+
+func mergePhaseTimes(timesA, timesB map[string]Histogram) map[string]Histogram {
+	if timesA == nil && timesB == nil {
+		return nil
+	}
+
+	merged := make(map[string]Histogram)
+	for phase, histogram := range timesA {
+		merged[phase] = histogram
+	}
+	for phase, histogram := range timesB {
+		merged[phase] = merged[phase].Merge(histogram)
+	}
+
+	return merged
+}
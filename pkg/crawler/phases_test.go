@@ -0,0 +1,51 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAsyncCrawlPhaseTimes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Length", "20000")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte(strings.Repeat("a", 20000)))
+	}))
+	defer server.Close()
+
+	crawler := NewCrawler(CrawlConfig{
+		Throttle:   1,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+	})
+	defer crawler.CloseIdleConnections()
+
+	stats, err := crawler.Crawl([]string{server.URL + "/a", server.URL + "/b"}, make(chan struct{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ttfb := stats.PhaseTimes[PhaseTTFB]; ttfb.Count != 2 || ttfb.Percentile(50) < 20*time.Millisecond {
+		t.Errorf("unexpected time to first byte stats %+v", ttfb)
+	}
+	if transfer := stats.PhaseTimes[PhaseTransfer]; transfer.Count != 2 || transfer.Max < 30*time.Millisecond {
+		t.Errorf("unexpected transfer stats %+v", transfer)
+	}
+	if tcp := stats.PhaseTimes[PhaseTCP]; tcp.Count != 1 {
+		t.Errorf("expected only the first connection to be measured, got %d", tcp.Count)
+	}
+	if _, ok := stats.PhaseTimes[PhaseTLS]; ok {
+		t.Errorf("expected no TLS handshake to be measured")
+	}
+
+	merged := MergeCrawlStats(stats, stats)
+	if merged.PhaseTimes[PhaseTTFB].Count != 4 {
+		t.Errorf("expected phase times to be merged, got %d", merged.PhaseTimes[PhaseTTFB].Count)
+	}
+}
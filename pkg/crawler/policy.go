@@ -0,0 +1,391 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of policy rules. Only failed error rules change the exit code.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// PolicyRule bounds the value of a crawl metric, globally or for a URL group.
+// Times are in milliseconds and rates in percent.
+type PolicyRule struct {
+	Name     string   `json:"name" yaml:"name"`
+	Metric   string   `json:"metric" yaml:"metric"`
+	Group    string   `json:"group,omitempty" yaml:"group"`
+	Max      *float64 `json:"max,omitempty" yaml:"max"`
+	Min      *float64 `json:"min,omitempty" yaml:"min"`
+	Severity string   `json:"severity" yaml:"severity"`
+	ExitCode int      `json:"exit-code" yaml:"exit-code"`
+}
+
+// RuleResult is the outcome of a policy rule evaluated against crawl
+// statistics
+type RuleResult struct {
+	Rule     PolicyRule `json:"rule"`
+	Value    float64    `json:"value"`
+	HasValue bool       `json:"has-value"`
+	Passed   bool       `json:"passed"`
+}
+
+// timeMetrics lists the response time metrics, also available per timing
+// phase, such as "ttfb-p95"
+var timeMetrics = map[string]bool{
+	"avg": true, "max": true, "p50": true, "p90": true, "p95": true, "p99": true,
+}
+
+// groupMetrics lists the metrics which can be computed per URL group, in
+// addition to time metrics
+var groupMetrics = map[string]bool{
+	"crawled": true, "error-rate": true, "errors": true,
+}
+
+// globalMetrics lists the metrics which can only be computed for the whole
+// crawl, in addition to group metrics and phase metrics
+var globalMetrics = map[string]bool{
+	"cert-days-left": true, "cert-issues": true, "assertion-failures": true, "soft-404": true,
+	"security-issues": true, "revalidation-issues": true, "compression-issues": true,
+	"uncacheable": true, "cache-hit-ratio": true, "orphans": true, "not-in-sitemap": true,
+	"broken-anchors": true, "contact-link-issues": true, "mixed-content": true, "canonical-issues": true,
+	"seo-issues": true,
+}
+
+// Compile validates the rule and sets its defaults. It must be called before
+// the rule is evaluated.
+func (rule *PolicyRule) Compile() error {
+	if rule.Metric == "" {
+		return fmt.Errorf("policy rule '%s' has no metric", rule.Name)
+	}
+	if !isPolicyMetric(rule.Metric, rule.Group != "") {
+		if rule.Group != "" {
+			return fmt.Errorf("metric '%s' can not be used for URL groups", rule.Metric)
+		}
+		return fmt.Errorf("unknown policy metric '%s'", rule.Metric)
+	}
+	if rule.Max == nil && rule.Min == nil {
+		return fmt.Errorf("policy rule on '%s' has neither a max nor a min", rule.Metric)
+	}
+
+	switch rule.Severity {
+	case "":
+		rule.Severity = SeverityError
+	case SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("invalid severity '%s' for policy rule on '%s'", rule.Severity, rule.Metric)
+	}
+
+	if rule.ExitCode == 0 {
+		rule.ExitCode = 1
+	}
+
+	if rule.Name == "" {
+		rule.Name = rule.Metric
+		if rule.Group != "" {
+			rule.Name += " of " + rule.Group
+		}
+	}
+
+	return nil
+}
+
+func isPolicyMetric(metric string, forGroup bool) bool {
+	if timeMetrics[metric] || groupMetrics[metric] || isStatusMetric(metric) {
+		return true
+	}
+	if forGroup {
+		return false
+	}
+	if globalMetrics[metric] {
+		return true
+	}
+
+	for _, phase := range Phases {
+		if strings.HasPrefix(metric, phase+"-") && timeMetrics[strings.TrimPrefix(metric, phase+"-")] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isStatusMetric returns whether the metric counts a status code or class,
+// such as "status-404" or "status-5xx"
+func isStatusMetric(metric string) bool {
+	if !strings.HasPrefix(metric, "status-") {
+		return false
+	}
+
+	_, err := ParseStatusSet(strings.TrimPrefix(metric, "status-"))
+	return err == nil
+}
+
+// LoadPolicy reads and compiles a list of policy rules from a YAML file, if
+// its extension is .yaml or .yml, or from a JSON file otherwise
+func LoadPolicy(path string) (rules []PolicyRule, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &rules)
+	default:
+		err = json.Unmarshal(content, &rules)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file '%s': %v", path, err)
+	}
+
+	for i := range rules {
+		err = rules[i].Compile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// bodyMetrics lists the metrics collected from page bodies
+var bodyMetrics = map[string]bool{
+	"soft-404": true, "orphans": true, "not-in-sitemap": true, "broken-anchors": true,
+	"contact-link-issues": true, "mixed-content": true, "canonical-issues": true, "seo-issues": true,
+}
+
+// EnablePolicyChecks returns the configuration passed with the checks
+// collecting the metrics of the rules passed turned on, so that rules do not
+// pass for lack of data. Rules on metrics which can not be collected with the
+// configuration passed, such as assertion failures without any assertion,
+// are rejected.
+func EnablePolicyChecks(rules []PolicyRule, config CrawlConfig) (CrawlConfig, error) {
+	withoutBody := (config.HTTP.Method != "" && config.HTTP.Method != http.MethodGet) ||
+		config.HTTP.Body == DiscardBody
+
+	for _, rule := range rules {
+		if bodyMetrics[rule.Metric] && withoutBody {
+			return config, fmt.Errorf("policy rule '%s' requires downloading page bodies", rule.Name)
+		}
+
+		switch rule.Metric {
+		case "cert-days-left", "cert-issues":
+			config.CheckCertificates = true
+		case "assertion-failures":
+			if len(config.HTTP.Assertions) == 0 {
+				return config, fmt.Errorf("policy rule '%s' requires assertions", rule.Name)
+			}
+		case "compression-issues":
+			if config.HTTP.CompressionMinSize <= 0 {
+				return config, fmt.Errorf("policy rule '%s' requires a minimum size of compressed responses",
+					rule.Name)
+			}
+		case "soft-404":
+			config.DetectSoft404 = true
+		case "security-issues":
+			config.HTTP.AuditSecurity = true
+		case "revalidation-issues":
+			config.HTTP.CheckRevalidation = true
+		case "uncacheable", "cache-hit-ratio":
+			config.HTTP.CacheReport = true
+		case "orphans", "not-in-sitemap":
+			config.Coverage = true
+		case "broken-anchors":
+			config.CheckAnchors = true
+		case "contact-link-issues":
+			config.CheckContactLinks = true
+		case "mixed-content":
+			config.HTTP.CheckMixedContent = true
+		case "canonical-issues":
+			config.HTTP.CheckCanonicals = true
+		case "seo-issues":
+			config.HTTP.AuditSEO = true
+		}
+	}
+
+	return config, nil
+}
+
+// EvaluatePolicy evaluates each rule against the crawling statistics passed.
+// Rules whose metric has no value, such as response time percentiles without
+// any 200 response, fail.
+func EvaluatePolicy(rules []PolicyRule, stats CrawlStats) []RuleResult {
+	results := make([]RuleResult, 0, len(rules))
+	for _, rule := range rules {
+		result := RuleResult{Rule: rule}
+		result.Value, result.HasValue = metricValue(rule.Metric, rule.Group, stats)
+		result.Passed = result.HasValue &&
+			(rule.Max == nil || result.Value <= *rule.Max) &&
+			(rule.Min == nil || result.Value >= *rule.Min)
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// PolicyExitCode returns the exit code of the first failed error rule, or 0
+// if all of them passed
+func PolicyExitCode(results []RuleResult) int {
+	for _, result := range results {
+		if !result.Passed && result.Rule.Severity == SeverityError {
+			return result.Rule.ExitCode
+		}
+	}
+
+	return 0
+}
+
+// String describes the bounds of the rule, such as "<= 800"
+func (rule PolicyRule) String() string {
+	var bounds []string
+	if rule.Min != nil {
+		bounds = append(bounds, ">= "+strconv.FormatFloat(*rule.Min, 'f', -1, 64))
+	}
+	if rule.Max != nil {
+		bounds = append(bounds, "<= "+strconv.FormatFloat(*rule.Max, 'f', -1, 64))
+	}
+	return strings.Join(bounds, " and ")
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+// histogramValue returns a statistic of the histogram, such as "avg" or "p95"
+func histogramValue(statistic string, histogram Histogram) (float64, bool) {
+	if histogram.Count == 0 {
+		return 0, false
+	}
+
+	switch statistic {
+	case "avg":
+		return milliseconds(histogram.Mean()), true
+	case "max":
+		return milliseconds(histogram.Max), true
+	}
+
+	if strings.HasPrefix(statistic, "p") {
+		percentile, err := strconv.ParseFloat(strings.TrimPrefix(statistic, "p"), 64)
+		if err == nil {
+			return milliseconds(histogram.Percentile(percentile)), true
+		}
+	}
+
+	return 0, false
+}
+
+func statusCount(metric string, statusCodes map[int]int) float64 {
+	set, _ := ParseStatusSet(strings.TrimPrefix(metric, "status-"))
+
+	count := 0
+	for code, codeCount := range statusCodes {
+		if set.Contains(code) {
+			count += codeCount
+		}
+	}
+	return float64(count)
+}
+
+func metricValue(metric string, group string, stats CrawlStats) (float64, bool) {
+	total := stats.Total
+	statusCodes := stats.StatusCodes
+	unexpected := len(stats.UnexpectedStatuses)
+	// Unexpected URLs are listed once across iterations, unlike the
+	// responses counted in the total
+	unexpectedResponses := total - stats.ExpectedStatuses
+	responseTimes := stats.ResponseTimes
+
+	if group != "" {
+		groupStats, ok := stats.Groups[group]
+		if !ok {
+			return 0, false
+		}
+		total = groupStats.Total
+		statusCodes = groupStats.StatusCodes
+		unexpected = len(groupStats.Unexpected)
+		unexpectedResponses = unexpected
+		responseTimes = groupStats.ResponseTimes
+	}
+
+	switch metric {
+	case "crawled":
+		return float64(total), true
+	case "errors":
+		return float64(unexpected), true
+	case "error-rate":
+		if total == 0 {
+			return 0, false
+		}
+		return float64(unexpectedResponses) * 100 / float64(total), true
+	case "avg", "max", "p50", "p90", "p95", "p99":
+		return histogramValue(metric, responseTimes)
+	case "cert-days-left":
+		if len(stats.Certificates) == 0 {
+			return 0, false
+		}
+		daysLeft := math.MaxInt32
+		for _, info := range stats.Certificates {
+			if !info.NotAfter.IsZero() && info.DaysLeft < daysLeft {
+				daysLeft = info.DaysLeft
+			}
+		}
+		return float64(daysLeft), daysLeft != math.MaxInt32
+	case "cert-issues":
+		return float64(stats.CertificateIssues()), true
+	case "assertion-failures":
+		return float64(len(stats.AssertionFailures)), true
+	case "soft-404":
+		return float64(len(stats.Soft404Urls)), true
+	case "security-issues":
+		return float64(len(stats.SecurityIssues)), true
+	case "revalidation-issues":
+		return float64(len(stats.RevalidationIssues)), true
+	case "compression-issues":
+		return float64(len(stats.CompressionIssues)), true
+	case "broken-anchors":
+		return float64(len(stats.BrokenAnchors)), true
+	case "contact-link-issues":
+		return float64(len(stats.ContactLinkIssues)), true
+	case "mixed-content":
+		return float64(len(stats.MixedContent)), true
+	case "canonical-issues":
+		return float64(len(stats.CanonicalIssues)), true
+	case "seo-issues":
+		return float64(stats.SEOIssueCount()), true
+	case "orphans":
+		return float64(len(stats.Orphans)), true
+	case "not-in-sitemap":
+		return float64(len(stats.NotInSitemap)), true
+	case "uncacheable":
+		return float64(len(stats.UncacheableUrls)), true
+	case "cache-hit-ratio":
+		if stats.Cache.Total() == 0 {
+			return 0, false
+		}
+		return stats.Cache.HitRatio() * 100, true
+	}
+
+	if isStatusMetric(metric) {
+		return statusCount(metric, statusCodes), true
+	}
+
+	for _, phase := range Phases {
+		if strings.HasPrefix(metric, phase+"-") {
+			return histogramValue(strings.TrimPrefix(metric, phase+"-"), stats.PhaseTimes[phase])
+		}
+	}
+
+	return 0, false
+}
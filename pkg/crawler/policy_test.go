@@ -0,0 +1,169 @@
+package crawler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func TestPolicyRuleCompile(t *testing.T) {
+	valid := []PolicyRule{
+		{Metric: "p95", Max: float(800)},
+		{Metric: "p95", Group: "products", Max: float(800)},
+		{Metric: "status-5xx", Max: float(0)},
+		{Metric: "ttfb-p99", Max: float(300)},
+		{Metric: "cert-days-left", Min: float(14), Severity: SeverityWarning},
+	}
+	for _, rule := range valid {
+		if err := rule.Compile(); err != nil {
+			t.Errorf("expected rule %+v to be valid, got %v", rule, err)
+		}
+	}
+
+	invalid := []PolicyRule{
+		{Max: float(1)},
+		{Metric: "p95"},
+		{Metric: "unknown", Max: float(1)},
+		{Metric: "status-6zz", Max: float(1)},
+		{Metric: "cert-days-left", Group: "products", Min: float(14)},
+		{Metric: "ttfb-errors", Max: float(1)},
+		{Metric: "p95", Max: float(800), Severity: "fatal"},
+	}
+	for _, rule := range invalid {
+		if err := rule.Compile(); err == nil {
+			t.Errorf("expected rule %+v to be invalid", rule)
+		}
+	}
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	var products Histogram
+	for i := 1; i <= 100; i++ {
+		products.Record(time.Duration(i*10) * time.Millisecond)
+	}
+
+	stats := CrawlStats{
+		Total:              200,
+		StatusCodes:        map[int]int{200: 197, 404: 1, 503: 2},
+		ExpectedStatuses:   197,
+		UnexpectedStatuses: make([]CrawlResult, 3),
+		Groups: map[string]GroupStats{
+			"products": {Total: 100, StatusCodes: map[int]int{200: 100}, ResponseTimes: products},
+		},
+		Certificates: map[string]CertificateInfo{
+			"foo.bar": {NotAfter: time.Now().Add(240 * time.Hour), DaysLeft: 10},
+		},
+	}
+
+	rules := []PolicyRule{
+		{Name: "products p95", Metric: "p95", Group: "products", Max: float(800)},
+		{Metric: "error-rate", Max: float(0.5), Severity: SeverityWarning},
+		{Metric: "status-5xx", Max: float(0), ExitCode: 2},
+		{Metric: "cert-days-left", Min: float(14), ExitCode: 3},
+		{Metric: "p95", Group: "missing", Max: float(800)},
+	}
+	for i := range rules {
+		if err := rules[i].Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results := EvaluatePolicy(rules, stats)
+
+	expected := []struct {
+		passed bool
+		value  float64
+	}{
+		{false, 959}, {false, 1.5}, {false, 2}, {false, 10}, {false, 0},
+	}
+	for i, result := range results {
+		if result.Passed != expected[i].passed || (result.HasValue && result.Value != expected[i].value) {
+			t.Errorf("unexpected result for rule '%s': %+v", result.Rule.Name, result)
+		}
+	}
+	if results[4].HasValue {
+		t.Errorf("expected unknown group to have no value")
+	}
+
+	if code := PolicyExitCode(results); code != 1 {
+		t.Errorf("expected default exit code of first failed rule, got %d", code)
+	}
+	if code := PolicyExitCode(results[1:]); code != 2 {
+		t.Errorf("expected exit code of first failed error rule, got %d", code)
+	}
+	if code := PolicyExitCode(results[1:2]); code != 0 {
+		t.Errorf("expected warnings not to change the exit code, got %d", code)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	files := map[string]string{
+		"policy.json": `[{"name": "fast", "metric": "p95", "max": 500},
+			{"metric": "errors", "group": "checkout", "max": 0, "severity": "warning", "exit-code": 3}]`,
+		"policy.yaml": `- name: fast
+  metric: p95
+  max: 500
+- metric: errors
+  group: checkout
+  max: 0
+  severity: warning
+  exit-code: 3
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		rules, err := LoadPolicy(path)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+			continue
+		}
+		if len(rules) != 2 || rules[0].Name != "fast" || *rules[0].Max != 500 || rules[0].Severity != SeverityError {
+			t.Errorf("%s: unexpected first rule %+v", name, rules)
+			continue
+		}
+		if rules[1].Group != "checkout" || *rules[1].Max != 0 || rules[1].Severity != SeverityWarning ||
+			rules[1].ExitCode != 3 {
+			t.Errorf("%s: unexpected second rule %+v", name, rules[1])
+		}
+	}
+}
+
+func TestEnablePolicyChecks(t *testing.T) {
+	rules := []PolicyRule{
+		{Metric: "cert-days-left", Min: float(14)},
+		{Metric: "seo-issues", Max: float(0)},
+		{Metric: "uncacheable", Max: float(0)},
+	}
+	for i := range rules {
+		if err := rules[i].Compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := EnablePolicyChecks(rules, CrawlConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.CheckCertificates || !config.HTTP.AuditSEO || !config.HTTP.CacheReport {
+		t.Errorf("expected the checks of the policy metrics to be enabled, got %+v", config)
+	}
+
+	if _, err = EnablePolicyChecks(rules, CrawlConfig{HTTP: HTTPConfig{Body: DiscardBody}}); err == nil {
+		t.Errorf("expected body metrics to be rejected when discarding bodies")
+	}
+
+	assertions := []PolicyRule{{Name: "assertions", Metric: "assertion-failures", Max: float(0)}}
+	if _, err = EnablePolicyChecks(assertions, CrawlConfig{}); err == nil {
+		t.Errorf("expected assertion failures to be rejected without assertions")
+	}
+}
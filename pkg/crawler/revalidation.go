@@ -0,0 +1,69 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// checkRevalidation requests the URL again with the validators of the first
+// response, and returns the issues found if the server does not answer with
+// a 304 Not Modified status code
+func checkRevalidation(client *http.Client, urlStr string, config HTTPConfig, header http.Header) []string {
+	etag := header.Get("ETag")
+	lastModified := header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return []string{"response has no ETag or Last-Modified validator"}
+	}
+
+	conditionalConfig := config
+	conditionalConfig.Headers = config.Headers.Clone()
+	if conditionalConfig.Headers == nil {
+		conditionalConfig.Headers = http.Header{}
+	}
+	if etag != "" {
+		conditionalConfig.Headers.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		conditionalConfig.Headers.Set("If-Modified-Since", lastModified)
+	}
+
+	method := config.Method
+	if method == "" || method == MethodHeadThenGet {
+		method = http.MethodGet
+	}
+
+	resp, _, err := doRequest(client, method, urlStr, conditionalConfig)
+	if err != nil {
+		return []string{fmt.Sprint("conditional request failed: ", err)}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	var issues []string
+	if newETag := resp.Header.Get("ETag"); etag != "" && newETag != "" && !weakETagMatch(newETag, etag) {
+		issues = append(issues, fmt.Sprintf("ETag is unstable between requests ('%s' then '%s')", etag, newETag))
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return issues
+	}
+
+	if newLastModified := resp.Header.Get("Last-Modified"); lastModified != "" && newLastModified != lastModified {
+		issues = append(issues, fmt.Sprintf("Last-Modified is unstable between requests ('%s' then '%s')",
+			lastModified, newLastModified))
+	}
+
+	if len(issues) == 0 {
+		issues = append(issues, fmt.Sprintf("conditional request returned status %d instead of 304", resp.StatusCode))
+	}
+
+	return issues
+}
+
+// weakETagMatch returns whether both entity tags passed match using the weak
+// comparison of RFC 9110, which ignores the weakness indicator, since
+// compression middlewares often weaken the ETag of responses
+func weakETagMatch(etagA string, etagB string) bool {
+	return strings.TrimPrefix(etagA, "W/") == strings.TrimPrefix(etagB, "W/")
+}
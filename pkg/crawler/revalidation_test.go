@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncCrawlChecksRevalidation(t *testing.T) {
+	var mutex sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/supported":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/weakened":
+			if r.Header.Get("If-None-Match") != "" {
+				w.Header().Set("ETag", `W/"v1"`)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+		case "/ignored":
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		case "/unstable":
+			mutex.Lock()
+			requests++
+			w.Header().Set("ETag", `"`+strconv.Itoa(requests)+`"`)
+			mutex.Unlock()
+		}
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second, CheckRevalidation: true},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+	}
+
+	urls := []string{server.URL + "/supported", server.URL + "/weakened", server.URL + "/ignored",
+		server.URL + "/unstable", server.URL + "/missing"}
+	stats, err := AsyncCrawl(urls, config, make(chan struct{}))
+	if err == nil {
+		t.Errorf("expected an error to be reported")
+	}
+
+	issues := make(map[string][]string)
+	for _, result := range stats.RevalidationIssues {
+		issues[result.URL] = result.Issues
+	}
+
+	for _, path := range []string{"/supported", "/weakened"} {
+		if _, ok := issues[server.URL+path]; ok {
+			t.Errorf("expected no issue for supported revalidation of %s, got %v", path, issues[server.URL+path])
+		}
+	}
+	for _, path := range []string{"/ignored", "/unstable", "/missing"} {
+		if len(issues[server.URL+path]) != 1 {
+			t.Errorf("expected one issue for %s, got %v", path, issues[server.URL+path])
+		}
+	}
+	if stats.Total != len(urls) {
+		t.Errorf("expected conditional requests not to be counted, got %d crawled", stats.Total)
+	}
+}
+
+func TestMergeRevalidationIssues(t *testing.T) {
+	issue := CrawlResult{URL: "http://foo.bar/", Issues: []string{"response has no ETag or Last-Modified validator"}}
+	stats := CrawlStats{RevalidationIssues: []CrawlResult{issue}}
+	if merged := MergeCrawlStats(stats, stats); len(merged.RevalidationIssues) != 1 {
+		t.Errorf("expected revalidation issues to be listed once across iterations, got %+v",
+			merged.RevalidationIssues)
+	}
+}
@@ -0,0 +1,95 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultRequiredSecurityHeaders lists the headers required on HTML pages by
+// the security audit, unless configured otherwise
+var DefaultRequiredSecurityHeaders = []string{
+	"Strict-Transport-Security",
+	"Content-Security-Policy",
+	"X-Content-Type-Options",
+	"X-Frame-Options",
+	"Referrer-Policy",
+}
+
+// AuditSecurityHeaders returns the security issues found in the headers of
+// an HTML response: missing or misconfigured required headers, and cookies
+// set without the Secure, HttpOnly or SameSite attributes.
+// Strict-Transport-Security is only required on HTTPS responses, and
+// X-Frame-Options is satisfied by a CSP 'frame-ancestors' directive.
+func AuditSecurityHeaders(header http.Header, isHTTPS bool, required []string) (issues []string) {
+	csp := strings.ToLower(header.Get("Content-Security-Policy"))
+
+	for _, name := range required {
+		name = http.CanonicalHeaderKey(name)
+		value := strings.ToLower(strings.TrimSpace(header.Get(name)))
+
+		switch name {
+		case "Strict-Transport-Security":
+			if !isHTTPS {
+				continue
+			}
+			if value == "" {
+				issues = append(issues, "missing Strict-Transport-Security header")
+			} else if maxAge, ok := hstsMaxAge(value); !ok || maxAge <= 0 {
+				issues = append(issues, "Strict-Transport-Security header has no positive max-age")
+			}
+		case "X-Content-Type-Options":
+			if value != "nosniff" {
+				issues = append(issues, "X-Content-Type-Options header is not 'nosniff'")
+			}
+		case "X-Frame-Options":
+			if value == "" && !strings.Contains(csp, "frame-ancestors") {
+				issues = append(issues, "missing X-Frame-Options header or CSP frame-ancestors directive")
+			} else if value != "" && value != "deny" && value != "sameorigin" {
+				issues = append(issues, "X-Frame-Options header is neither 'DENY' nor 'SAMEORIGIN'")
+			}
+		default:
+			if value == "" {
+				issues = append(issues, fmt.Sprintf("missing %s header", name))
+			}
+		}
+	}
+
+	response := http.Response{Header: header}
+	for _, cookie := range response.Cookies() {
+		var missing []string
+		if !cookie.Secure {
+			missing = append(missing, "Secure")
+		}
+		if !cookie.HttpOnly {
+			missing = append(missing, "HttpOnly")
+		}
+		if cookie.SameSite == 0 {
+			missing = append(missing, "SameSite")
+		}
+
+		if len(missing) > 0 {
+			issues = append(issues, fmt.Sprintf("cookie '%s' is set without %s", cookie.Name,
+				strings.Join(missing, ", ")))
+		}
+	}
+
+	return
+}
+
+// hstsMaxAge returns the value of the max-age directive of a
+// Strict-Transport-Security header, and whether it is set and valid
+func hstsMaxAge(value string) (int64, bool) {
+	for _, directive := range strings.Split(value, ";") {
+		name, maxAge, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(strings.Trim(strings.TrimSpace(maxAge), `"`), 10, 64)
+		return seconds, err == nil
+	}
+
+	return 0, false
+}
@@ -0,0 +1,119 @@
+package crawler
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAuditSecurityHeaders(t *testing.T) {
+	secureHeaders := map[string]string{
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+		"Content-Security-Policy":   "default-src 'self'",
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+	}
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		cookies        []string
+		isHTTPS        bool
+		required       []string
+		expectedIssues int
+	}{
+		{
+			name:           "All headers present",
+			headers:        secureHeaders,
+			isHTTPS:        true,
+			required:       DefaultRequiredSecurityHeaders,
+			expectedIssues: 0,
+		},
+		{
+			name:           "No headers over HTTPS",
+			isHTTPS:        true,
+			required:       DefaultRequiredSecurityHeaders,
+			expectedIssues: 5,
+		},
+		{
+			name:           "No headers over HTTP",
+			isHTTPS:        false,
+			required:       DefaultRequiredSecurityHeaders,
+			expectedIssues: 4,
+		},
+		{
+			name: "Frame ancestors and invalid values",
+			headers: map[string]string{
+				"Strict-Transport-Security": "max-age=0",
+				"Content-Security-Policy":   "frame-ancestors 'none'",
+				"X-Content-Type-Options":    "sniff",
+				"Referrer-Policy":           "no-referrer",
+			},
+			isHTTPS:        true,
+			required:       DefaultRequiredSecurityHeaders,
+			expectedIssues: 2,
+		},
+		{
+			name:           "Custom required headers",
+			headers:        secureHeaders,
+			isHTTPS:        true,
+			required:       []string{"permissions-policy"},
+			expectedIssues: 1,
+		},
+		{
+			name:    "Insecure cookies",
+			headers: secureHeaders,
+			cookies: []string{
+				"session=abc; Secure; HttpOnly; SameSite=Lax",
+				"tracking=xyz; Path=/",
+			},
+			isHTTPS:        true,
+			required:       DefaultRequiredSecurityHeaders,
+			expectedIssues: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for name, value := range tt.headers {
+				header.Set(name, value)
+			}
+			for _, cookie := range tt.cookies {
+				header.Add("Set-Cookie", cookie)
+			}
+
+			issues := AuditSecurityHeaders(header, tt.isHTTPS, tt.required)
+			if len(issues) != tt.expectedIssues {
+				t.Errorf("expected %d issues, got %v", tt.expectedIssues, issues)
+			}
+		})
+	}
+}
+
+func TestHSTSMaxAge(t *testing.T) {
+	tests := map[string]int64{
+		"max-age=31536000; includesubdomains": 31536000,
+		"includesubdomains; max-age=0315":     315,
+		`max-age="600"`:                       600,
+		"max-age=0":                           0,
+		"max-age=":                            -1,
+		"includesubdomains":                   -1,
+	}
+
+	for value, expected := range tests {
+		maxAge, ok := hstsMaxAge(value)
+		if (expected < 0 && ok) || (expected >= 0 && (!ok || maxAge != expected)) {
+			t.Errorf("unexpected max-age %d (%t) for '%s'", maxAge, ok, value)
+		}
+	}
+}
+
+func TestMergeSecurityIssues(t *testing.T) {
+	issue := CrawlResult{URL: "https://foo.bar/", Issues: []string{"missing X-Frame-Options header"}}
+	stats := CrawlStats{SecurityIssues: []CrawlResult{issue}}
+	if merged := MergeCrawlStats(stats, stats); len(merged.SecurityIssues) != 1 {
+		t.Errorf("expected security issues to be listed once across iterations, got %+v",
+			merged.SecurityIssues)
+	}
+}
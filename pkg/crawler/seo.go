@@ -0,0 +1,281 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Types of SEO issues, under which pages are reported
+const (
+	SEOMissingTitle         = "missing-title"
+	SEODuplicateTitle       = "duplicate-title"
+	SEOTitleLength          = "title-length"
+	SEOMissingDescription   = "missing-description"
+	SEODuplicateDescription = "duplicate-description"
+	SEODescriptionLength    = "description-length"
+	SEOMissingH1            = "missing-h1"
+	SEOMultipleH1           = "multiple-h1"
+	SEONoIndex              = "noindex"
+	SEOMissingAlt           = "missing-alt"
+)
+
+// SEOIssueTypes lists the types of SEO issues in reporting order
+var SEOIssueTypes = []string{
+	SEOMissingTitle, SEODuplicateTitle, SEOTitleLength,
+	SEOMissingDescription, SEODuplicateDescription, SEODescriptionLength,
+	SEOMissingH1, SEOMultipleH1, SEONoIndex, SEOMissingAlt,
+}
+
+// LengthRange bounds the length in characters of a text
+type LengthRange struct {
+	Min int
+	Max int
+}
+
+// SEOConfig holds the bounds of the SEO audit. Default ranges apply if
+// unset.
+type SEOConfig struct {
+	TitleLength       LengthRange
+	DescriptionLength LengthRange
+}
+
+// Default length ranges of page titles and meta descriptions
+var (
+	DefaultTitleLength       = LengthRange{Min: 10, Max: 60}
+	DefaultDescriptionLength = LengthRange{Min: 50, Max: 160}
+)
+
+// ParseLengthRange converts a description of a length range, such as
+// "10-60", into a LengthRange
+func ParseLengthRange(value string) (LengthRange, error) {
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) != 2 {
+		return LengthRange{}, fmt.Errorf("invalid length range '%s', expected 'min-max'", value)
+	}
+
+	min, errMin := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	max, errMax := strconv.Atoi(strings.TrimSpace(bounds[1]))
+	if errMin != nil || errMax != nil || min < 0 || max < min {
+		return LengthRange{}, fmt.Errorf("invalid length range '%s', expected 'min-max'", value)
+	}
+
+	return LengthRange{Min: min, Max: max}, nil
+}
+
+func (lengthRange LengthRange) check(name string, text string) (string, bool) {
+	length := utf8.RuneCountInString(text)
+	if length < lengthRange.Min {
+		return fmt.Sprintf("%s is %d characters long, less than %d", name, length, lengthRange.Min), false
+	}
+	if length > lengthRange.Max {
+		return fmt.Sprintf("%s is %d characters long, more than %d", name, length, lengthRange.Max), false
+	}
+	return "", true
+}
+
+// PageSEO holds the on-page SEO elements of an html page
+type PageSEO struct {
+	Title          string
+	HasTitle       bool
+	Description    string
+	HasDescription bool
+	H1Count        int
+	NoIndex        bool
+	// ImagesWithoutAlt lists the source of images without 'alt' attribute
+	ImagesWithoutAlt []string
+}
+
+// normalizeText trims the text passed and collapses its whitespaces
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// valuedRobotsDirectives are the robots directives which take a value after a
+// colon, and which must not be mistaken for a bot name
+var valuedRobotsDirectives = map[string]bool{
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+	"unavailable_after": true,
+}
+
+// robotsMetaNames are the names of robots meta tags targeting crawlers whose
+// name does not end with "bot"
+var robotsMetaNames = map[string]bool{
+	"robots":      true,
+	"slurp":       true,
+	"baiduspider": true,
+}
+
+// isRobotsMetaName returns whether the meta tag name passed holds robots
+// directives, for all crawlers or a given one such as "bingbot"
+func isRobotsMetaName(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return robotsMetaNames[name] || strings.HasSuffix(name, "bot") || strings.HasPrefix(name, "googlebot-")
+}
+
+// isNoIndex returns whether robots directives, such as "noindex, nofollow"
+// or "googlebot: none", prevent indexing. Directives of every bot are taken
+// into account, as in "googlebot: index, bingbot: noindex".
+func isNoIndex(directives string) bool {
+	for _, directive := range strings.Split(directives, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if separator := strings.Index(directive, ":"); separator >= 0 {
+			botName := strings.TrimSpace(directive[:separator])
+			if !valuedRobotsDirectives[botName] && !strings.ContainsAny(botName, " \t") {
+				directive = strings.TrimSpace(directive[separator+1:])
+			}
+		}
+
+		if directive == "noindex" || directive == "none" {
+			return true
+		}
+	}
+	return false
+}
+
+// auditDocumentSEO returns the on-page SEO elements of the html document and
+// its response header
+func auditDocumentSEO(doc *goquery.Document, header http.Header) *PageSEO {
+	page := &PageSEO{}
+
+	title := doc.Find("title").First()
+	page.Title = normalizeText(title.Text())
+	page.HasTitle = title.Length() > 0 && page.Title != ""
+
+	doc.Find("meta[name][content]").Each(func(i int, s *goquery.Selection) {
+		name, _ := s.Attr("name")
+		content, _ := s.Attr("content")
+		switch strings.ToLower(name) {
+		case "description":
+			if !page.HasDescription {
+				page.Description = normalizeText(content)
+				page.HasDescription = page.Description != ""
+			}
+		default:
+			if isRobotsMetaName(name) {
+				page.NoIndex = page.NoIndex || isNoIndex(content)
+			}
+		}
+	})
+	for _, value := range header.Values("X-Robots-Tag") {
+		page.NoIndex = page.NoIndex || isNoIndex(value)
+	}
+
+	page.H1Count = doc.Find("h1").Length()
+
+	doc.Find("img").Each(func(i int, s *goquery.Selection) {
+		if _, ok := s.Attr("alt"); !ok {
+			src, _ := s.Attr("src")
+			page.ImagesWithoutAlt = append(page.ImagesWithoutAlt, src)
+		}
+	})
+
+	return page
+}
+
+// seoAudit returns the SEO issues of the sitemap pages passed, by issue type.
+// Titles and descriptions are compared across all the sitemap pages.
+func seoAudit(urls []string, results map[string]*HTTPResponse, config SEOConfig) map[string][]CrawlResult {
+	titleLength := config.TitleLength
+	if titleLength == (LengthRange{}) {
+		titleLength = DefaultTitleLength
+	}
+	descriptionLength := config.DescriptionLength
+	if descriptionLength == (LengthRange{}) {
+		descriptionLength = DefaultDescriptionLength
+	}
+
+	var pages []*HTTPResponse
+	titles := make(map[string]int)
+	descriptions := make(map[string]int)
+	for _, rawURL := range urls {
+		result, ok := results[rawURL]
+		if !ok || result.SEO == nil {
+			continue
+		}
+		pages = append(pages, result)
+		if result.SEO.HasTitle {
+			titles[result.SEO.Title]++
+		}
+		if result.SEO.HasDescription {
+			descriptions[result.SEO.Description]++
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].URL < pages[j].URL
+	})
+
+	issues := make(map[string][]CrawlResult)
+	report := func(issueType string, result *HTTPResponse, details ...string) {
+		issues[issueType] = append(issues[issueType], CrawlResult{
+			URL:        result.URL,
+			StatusCode: result.StatusCode,
+			Issues:     details,
+		})
+	}
+
+	for _, result := range pages {
+		page := result.SEO
+
+		if !page.HasTitle {
+			report(SEOMissingTitle, result)
+		} else {
+			if count := titles[page.Title]; count > 1 {
+				report(SEODuplicateTitle, result,
+					fmt.Sprintf("title '%s' is used by %d pages", page.Title, count))
+			}
+			if issue, ok := titleLength.check("title", page.Title); !ok {
+				report(SEOTitleLength, result, issue)
+			}
+		}
+
+		if !page.HasDescription {
+			report(SEOMissingDescription, result)
+		} else {
+			if count := descriptions[page.Description]; count > 1 {
+				report(SEODuplicateDescription, result,
+					fmt.Sprintf("description '%s' is used by %d pages", page.Description, count))
+			}
+			if issue, ok := descriptionLength.check("description", page.Description); !ok {
+				report(SEODescriptionLength, result, issue)
+			}
+		}
+
+		if page.H1Count == 0 {
+			report(SEOMissingH1, result)
+		} else if page.H1Count > 1 {
+			report(SEOMultipleH1, result, fmt.Sprintf("%d h1 tags", page.H1Count))
+		}
+
+		if page.NoIndex {
+			report(SEONoIndex, result, "page listed in the sitemap is not indexable")
+		}
+
+		if len(page.ImagesWithoutAlt) > 0 {
+			var details []string
+			for _, src := range page.ImagesWithoutAlt {
+				details = append(details, "image '"+src+"' has no alt attribute")
+			}
+			report(SEOMissingAlt, result, details...)
+		}
+	}
+
+	return issues
+}
+
+// SEOIssueCount returns the number of SEO issues found, counting each page
+// once per issue type
+func (stats CrawlStats) SEOIssueCount() int {
+	count := 0
+	for _, results := range stats.SEOIssues {
+		count += len(results)
+	}
+	return count
+}
@@ -0,0 +1,193 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseLengthRange(t *testing.T) {
+	lengthRange, err := ParseLengthRange("10-60")
+	if err != nil || lengthRange != (LengthRange{Min: 10, Max: 60}) {
+		t.Errorf("unexpected range %+v (%v)", lengthRange, err)
+	}
+
+	for _, value := range []string{"", "60", "60-10", "-1-10", "a-b"} {
+		if _, err := ParseLengthRange(value); err == nil {
+			t.Errorf("expected an error for range '%s'", value)
+		}
+	}
+}
+
+func TestIsNoIndex(t *testing.T) {
+	cases := map[string]bool{
+		"noindex":                            true,
+		"NoIndex, nofollow":                  true,
+		"none":                               true,
+		"googlebot: noindex":                 true,
+		"index, follow":                      false,
+		"nofollow":                           false,
+		"max-snippet: 20, index":             false,
+		"max-snippet: 20, noindex":           true,
+		"noindex, max-image-preview:large":   true,
+		"max-image-preview:large, noindex":   true,
+		"googlebot: index, max-snippet: -1":  false,
+		"googlebot: max-snippet: 5, noindex": true,
+		"googlebot: index, bingbot: noindex": true,
+		"bingbot: nofollow, googlebot: none": true,
+		"unavailable_after: 2020-01-01":      false,
+	}
+	for directives, expected := range cases {
+		if isNoIndex(directives) != expected {
+			t.Errorf("expected noindex %t for '%s'", expected, directives)
+		}
+	}
+}
+
+func TestAuditDocumentSEONoIndex(t *testing.T) {
+	cases := []struct {
+		head     string
+		header   string
+		expected bool
+	}{
+		{`<meta name="robots" content="noindex">`, "", true},
+		{`<meta name="bingbot" content="noindex">`, "", true},
+		{`<meta name="GoogleBot-News" content="none">`, "", true},
+		{`<meta name="viewport" content="noindex">`, "", false},
+		{`<meta name="bingbot" content="nofollow">`, "bingbot: noindex", true},
+		{``, "googlebot: index, follow", false},
+	}
+	for _, tt := range cases {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.head + "</head></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := make(http.Header)
+		if tt.header != "" {
+			header.Set("X-Robots-Tag", tt.header)
+		}
+		if page := auditDocumentSEO(doc, header); page.NoIndex != tt.expected {
+			t.Errorf("expected noindex %t for '%s' and header '%s'", tt.expected, tt.head, tt.header)
+		}
+	}
+}
+
+func TestAsyncCrawlAuditSEO(t *testing.T) {
+	const description = "A description long enough to fit the default length bounds."
+	pages := map[string]string{
+		"/": `<title>Home of foo.bar</title><meta name="description" content="` + description + `">` +
+			`<h1>Home</h1><img src="/logo.png" alt="Logo"><img src="/spacer.gif" alt="">`,
+		"/copy": `<title>Home of foo.bar</title><meta name="Description" content="` + description + `">` +
+			`<h1>Copy</h1>`,
+		"/empty": `<title> </title><h1>One</h1><h1>Two</h1><img src="/photo.jpg">`,
+		"/short": `<title>Short</title><meta name="description" content="Too short">` +
+			`<meta name="robots" content="noindex, follow">`,
+		"/hidden": `<title>Hidden page of foo.bar</title><meta name="description" content="Another ` +
+			description + `"><h1>Hidden</h1>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path == "/hidden" {
+			w.Header().Set("X-Robots-Tag", "googlebot: none")
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><head>", page, "</head></html>")
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second, AuditSEO: true},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+	}
+	var urls []string
+	for path := range pages {
+		urls = append(urls, server.URL+path)
+	}
+
+	stats, err := AsyncCrawl(urls, config, make(chan struct{}))
+	if err == nil {
+		t.Errorf("expected SEO issues to be returned as an error")
+	}
+
+	expected := map[string][]string{
+		SEOMissingTitle:         {"/empty"},
+		SEODuplicateTitle:       {"/", "/copy"},
+		SEOTitleLength:          {"/short"},
+		SEOMissingDescription:   {"/empty"},
+		SEODuplicateDescription: {"/", "/copy"},
+		SEODescriptionLength:    {"/short"},
+		SEOMissingH1:            {"/short"},
+		SEOMultipleH1:           {"/empty"},
+		SEONoIndex:              {"/hidden", "/short"},
+		SEOMissingAlt:           {"/empty"},
+	}
+	if len(stats.SEOIssues) != len(expected) {
+		t.Errorf("expected %d SEO issue types, got %+v", len(expected), stats.SEOIssues)
+	}
+	for issueType, paths := range expected {
+		var found []string
+		for _, result := range stats.SEOIssues[issueType] {
+			found = append(found, strings.TrimPrefix(result.URL, server.URL))
+		}
+		sort.Strings(found)
+		if strings.Join(found, " ") != strings.Join(paths, " ") {
+			t.Errorf("expected %s issues for %v, got %v", issueType, paths, found)
+		}
+	}
+
+	merged := MergeCrawlStats(stats, stats)
+	for issueType, results := range stats.SEOIssues {
+		if len(merged.SEOIssues[issueType]) != len(results) {
+			t.Errorf("expected %s issues to be listed once across iterations, got %+v",
+				issueType, merged.SEOIssues[issueType])
+		}
+	}
+
+	if issues := stats.SEOIssues[SEOTitleLength]; len(issues) == 1 &&
+		issues[0].Issues[0] != "title is 5 characters long, less than 10" {
+		t.Errorf("unexpected title length issue '%s'", issues[0].Issues[0])
+	}
+	if issues := stats.SEOIssues[SEOMissingAlt]; len(issues) == 1 &&
+		issues[0].Issues[0] != "image '/photo.jpg' has no alt attribute" {
+		t.Errorf("unexpected missing alt issue '%s'", issues[0].Issues[0])
+	}
+	if stats.SEOIssueCount() != 13 {
+		t.Errorf("expected 13 SEO issues, got %d", stats.SEOIssueCount())
+	}
+}
+
+func TestAsyncCrawlAuditSEOLengthBounds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Short</title><meta name="description" content="Brief">`+
+			`</head><body><h1>Page</h1></body></html>`)
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   1,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second, AuditSEO: true},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		SEO: SEOConfig{
+			TitleLength:       LengthRange{Min: 5, Max: 10},
+			DescriptionLength: LengthRange{Min: 1, Max: 5},
+		},
+	}
+
+	stats, err := AsyncCrawl([]string{server.URL + "/"}, config, make(chan struct{}))
+	if err != nil || stats.SEOIssueCount() != 0 {
+		t.Errorf("expected no SEO issue, got %+v (%v)", stats.SEOIssues, err)
+	}
+}
@@ -0,0 +1,224 @@
+package crawler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultSoft404Threshold is the default similarity above which a page is
+// considered a soft-404. Not-found pages echoing different paths are more
+// than 80% similar, while other pages sharing the site layout are around 30%
+// similar to them.
+const DefaultSoft404Threshold = 0.7
+
+// soft404TitleBonus lowers the similarity required of pages whose title is
+// the one of the not-found page
+const soft404TitleBonus = 0.1
+
+// PageFingerprint summarizes the content of an HTML page, so that pages can
+// be compared with each other
+type PageFingerprint struct {
+	Title    string
+	Shingles []uint64
+}
+
+// notFoundTemplate is the page served by a host for URLs that do not exist
+type notFoundTemplate struct {
+	fingerprint PageFingerprint
+	finalURL    string
+}
+
+// FingerprintDocument computes the fingerprint of an HTML document from its
+// title and visible text
+func FingerprintDocument(doc *goquery.Document) PageFingerprint {
+	body := doc.Find("body").Clone()
+	body.Find("script, style, noscript, template").Remove()
+
+	return PageFingerprint{
+		Title:    strings.TrimSpace(doc.Find("title").First().Text()),
+		Shingles: shingles(strings.Fields(strings.ToLower(body.Text()))),
+	}
+}
+
+// shingles returns the sorted and unique hashes of the word 3-shingles of
+// the text provided
+func shingles(words []string) []uint64 {
+	const shingleSize = 3
+
+	set := make(map[uint64]bool)
+	for i := 0; i == 0 || i+shingleSize <= len(words); i++ {
+		end := i + shingleSize
+		if end > len(words) {
+			end = len(words)
+		}
+
+		hash := fnv.New64a()
+		hash.Write([]byte(strings.Join(words[i:end], " ")))
+		set[hash.Sum64()] = true
+	}
+
+	hashes := make([]uint64, 0, len(set))
+	for hash := range set {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashes[i] < hashes[j]
+	})
+
+	return hashes
+}
+
+// Similarity returns how similar two fingerprinted pages are, from 0 to 1,
+// as the Jaccard index of their shingles
+func (fingerprint PageFingerprint) Similarity(other PageFingerprint) float64 {
+	common := 0
+	for i, j := 0, 0; i < len(fingerprint.Shingles) && j < len(other.Shingles); {
+		switch {
+		case fingerprint.Shingles[i] == other.Shingles[j]:
+			common++
+			i++
+			j++
+		case fingerprint.Shingles[i] < other.Shingles[j]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	union := len(fingerprint.Shingles) + len(other.Shingles) - common
+	if union == 0 {
+		return 1
+	}
+	return float64(common) / float64(union)
+}
+
+// isSoft404 returns whether the page fingerprinted closely matches the
+// not-found template. Identical titles lower the body similarity required.
+func (template *notFoundTemplate) isSoft404(fingerprint PageFingerprint, threshold float64) (bool, float64) {
+	similarity := template.fingerprint.Similarity(fingerprint)
+	if template.fingerprint.Title != "" && template.fingerprint.Title == fingerprint.Title {
+		threshold -= soft404TitleBonus
+	}
+
+	return similarity >= threshold, similarity
+}
+
+func notFoundProbeURL(rawURL string) (host string, probeURL string, err error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := make([]byte, 16)
+	if _, err = rand.Read(token); err != nil {
+		return "", "", err
+	}
+
+	probe := url.URL{
+		Scheme: parsedURL.Scheme,
+		Host:   parsedURL.Host,
+		Path:   "/crowlet-not-found-" + hex.EncodeToString(token),
+	}
+
+	return parsedURL.Host, probe.String(), nil
+}
+
+// probeNotFoundTemplates requests a non-existent URL on each host of the
+// URLs passed which was not probed yet, and adds the not-found templates of
+// hosts responding with a 200 status code to the templates passed. Hosts
+// responding otherwise are added without template, so that they are probed
+// once.
+func probeNotFoundTemplates(urls []string, config CrawlConfig, templates map[string]*notFoundTemplate,
+	quit <-chan struct{}) {
+	probeURLs := make(map[string]string)
+	for _, rawURL := range urls {
+		host, probeURL, err := notFoundProbeURL(rawURL)
+		if err != nil {
+			log.Error("error creating not-found probe URL:", err)
+			continue
+		}
+		if _, probed := templates[host]; probed {
+			continue
+		}
+		if _, ok := probeURLs[host]; !ok {
+			probeURLs[host] = probeURL
+		}
+	}
+	if len(probeURLs) == 0 {
+		return
+	}
+
+	hostsByProbe := make(map[string]string)
+	probes := make([]string, 0, len(probeURLs))
+	for host, probeURL := range probeURLs {
+		log.Info("Probing not-found page of ", host)
+		hostsByProbe[probeURL] = host
+		probes = append(probes, probeURL)
+		templates[host] = nil
+	}
+
+	// Only request settings are kept, so that probes do not send any other
+	// request, such as revalidation requests
+	probeConfig := HTTPConfig{
+		User:        config.HTTP.User,
+		Pass:        config.HTTP.Pass,
+		Timeout:     config.HTTP.Timeout,
+		Transport:   config.HTTP.Transport,
+		NoRedirects: config.HTTP.NoRedirects,
+		Headers:     config.HTTP.Headers,
+		Fingerprint: true,
+	}
+
+	for result := range config.HTTPGetter.ConcurrentHTTPGet(probes, probeConfig, config.Throttle, quit) {
+		if result.StatusCode != 200 || result.Fingerprint == nil {
+			continue
+		}
+
+		host := hostsByProbe[result.URL]
+		log.Warn("Host ", host, " returns status 200 for non-existent pages")
+
+		template := &notFoundTemplate{fingerprint: *result.Fingerprint}
+		if result.Response != nil && result.Response.Request != nil &&
+			result.Response.Request.URL.String() != result.URL {
+			template.finalURL = result.Response.Request.URL.String()
+		}
+		templates[host] = template
+	}
+}
+
+// detectSoft404 returns the similarity of the result with the not-found
+// template of its host, and whether it is considered a soft-404
+func detectSoft404(result *HTTPResponse, templates map[string]*notFoundTemplate,
+	threshold float64) (bool, float64) {
+	if result.StatusCode != 200 || result.Fingerprint == nil {
+		return false, 0
+	}
+
+	parsedURL, err := url.Parse(result.URL)
+	if err != nil {
+		return false, 0
+	}
+
+	template := templates[parsedURL.Host]
+	if template == nil || result.URL == template.finalURL {
+		return false, 0
+	}
+
+	if threshold <= 0 {
+		threshold = DefaultSoft404Threshold
+	}
+
+	return template.isSoft404(*result.Fingerprint, threshold)
+}
+
+func soft404Issue(similarity float64) string {
+	return fmt.Sprintf("page is %.0f%% similar to the not-found page", similarity*100)
+}
@@ -0,0 +1,184 @@
+package crawler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const notFoundPage = `<html><head><title>Page not found</title></head><body>
+<nav>Home Products Blog About</nav>
+<h1>Oops</h1><p>Something went wrong, the page you are looking for does not exist or has been moved.</p>
+<p>Try searching our catalog or go back to the home page.</p>
+</body></html>`
+
+const productPage = `<html><head><title>Blue widget</title></head><body>
+<nav>Home Products Blog About</nav>
+<h1>Blue widget</h1><p>Our blue widget is hand made from recycled aluminium and comes with a lifetime warranty.</p>
+<p>Available in three sizes, shipped within two business days anywhere in Europe.</p>
+<script>var tracking = "Oops Something went wrong";</script>
+</body></html>`
+
+// siteHeader and siteFooter are the layout shared by all pages of a typical
+// shop, which not-found and product pages have in common
+const siteHeader = `<header><a href="/">Acme Outdoor</a>
+<nav>Men Women Kids Camping Hiking Climbing Sale Gift cards Stores Help</nav>
+<form><input placeholder="Search products, brands and guides"></form><a>Sign in</a><a>Cart (0)</a></header>`
+
+const siteFooter = `<footer><p>Free delivery on orders over 50 euros. Free returns within 30 days.</p>
+<ul><li>About us</li><li>Careers</li><li>Press</li><li>Sustainability</li></ul>
+<ul><li>Customer service</li><li>Delivery and returns</li><li>Size guide</li><li>Contact us</li></ul>
+<p>Subscribe to our newsletter and get 10% off your first order.</p>
+<p>© 2024 Acme Outdoor SAS. Terms and conditions. Privacy policy. Cookie settings. Legal notice.</p></footer>`
+
+func siteNotFoundPage(path string) string {
+	return `<html><head><title>Page not found | Acme Outdoor</title></head><body>` + siteHeader +
+		`<main><h1>We can't find that page</h1><p>The page ` + path + ` doesn't exist anymore or may have moved.</p>
+<p>Check the address for typos, or use the search bar above to find what you were looking for.</p>
+<a>Back to the home page</a> <a>Browse new arrivals</a></main>` + siteFooter + `</body></html>`
+}
+
+const siteProductPage = `<html><head><title>Trail 40L hiking backpack | Acme Outdoor</title></head><body>` +
+	siteHeader + `<main><nav>Home / Hiking / Backpacks</nav><h1>Trail 40L hiking backpack</h1><p>89.99 €</p>
+<p>A lightweight 40 litre backpack for multi-day hikes, with an adjustable back system, a rain cover stored
+in the lid and a hydration sleeve.</p>
+<ul><li>Weight: 1.2 kg</li><li>Volume: 40 L</li><li>Colour: forest green</li></ul>
+<p>Size: S/M M/L</p><button>Add to cart</button><p>In stock, delivered within 2 to 4 working days.</p>
+<h2>Customer reviews</h2><p>4.6 out of 5, 128 reviews. "Comfortable even when fully loaded."</p></main>` +
+	siteFooter + `</body></html>`
+
+func mustParseDocument(t *testing.T, html string) *goquery.Document {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestPageFingerprintSimilarity(t *testing.T) {
+	notFound := FingerprintDocument(mustParseDocument(t, notFoundPage))
+	product := FingerprintDocument(mustParseDocument(t, productPage))
+
+	if notFound.Title != "Page not found" {
+		t.Errorf("unexpected title '%s'", notFound.Title)
+	}
+	if similarity := notFound.Similarity(notFound); similarity != 1 {
+		t.Errorf("expected identical pages to be fully similar, got %f", similarity)
+	}
+	if similarity := notFound.Similarity(product); similarity >= DefaultSoft404Threshold-soft404TitleBonus {
+		t.Errorf("expected different pages not to be similar, got %f", similarity)
+	}
+
+	otherNotFound := FingerprintDocument(mustParseDocument(t,
+		strings.Replace(notFoundPage, "Oops", "Oops /deleted-product", 1)))
+	if similarity := notFound.Similarity(otherNotFound); similarity < DefaultSoft404Threshold {
+		t.Errorf("expected not-found pages of different paths to be similar, got %f", similarity)
+	}
+}
+
+func TestPageFingerprintSimilaritySiteLayout(t *testing.T) {
+	probe := FingerprintDocument(mustParseDocument(t, siteNotFoundPage("/crowlet-not-found-0123456789abcdef")))
+	deleted := FingerprintDocument(mustParseDocument(t, siteNotFoundPage("/products/discontinued-tent")))
+	product := FingerprintDocument(mustParseDocument(t, siteProductPage))
+
+	if similarity := probe.Similarity(deleted); similarity < DefaultSoft404Threshold {
+		t.Errorf("expected not-found pages to be similar, got %f", similarity)
+	}
+	// Pages sharing the site layout must not be similar even when their title
+	// matches the not-found page
+	if similarity := probe.Similarity(product); similarity >= DefaultSoft404Threshold-soft404TitleBonus {
+		t.Errorf("expected product page not to be similar to the not-found page, got %f", similarity)
+	}
+
+	template := &notFoundTemplate{fingerprint: probe}
+	if soft404, _ := template.isSoft404(deleted, DefaultSoft404Threshold); !soft404 {
+		t.Errorf("expected deleted page to be a soft-404")
+	}
+	if soft404, _ := template.isSoft404(product, DefaultSoft404Threshold); soft404 {
+		t.Errorf("expected product page not to be a soft-404")
+	}
+}
+
+func TestAsyncCrawlDetectsSoft404(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/product" {
+			w.Write([]byte(productPage))
+			return
+		}
+		w.Write([]byte(strings.Replace(notFoundPage, "Oops", "Oops "+r.URL.Path, 1)))
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:      2,
+		HTTP:          HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter:    &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		DetectSoft404: true,
+	}
+
+	urls := []string{server.URL + "/product", server.URL + "/deleted-product"}
+	stats, err := AsyncCrawl(urls, config, make(chan struct{}))
+	if err == nil {
+		t.Errorf("expected an error to be reported")
+	}
+	if stats.Total != 2 {
+		t.Errorf("expected probe requests not to be counted, got %d crawled", stats.Total)
+	}
+	if len(stats.Soft404Urls) != 1 || stats.Soft404Urls[0].URL != server.URL+"/deleted-product" {
+		t.Errorf("expected only deleted page to be a soft-404, got %v", stats.Soft404Urls)
+	}
+}
+
+func TestCrawlerProbesNotFoundPagesOnce(t *testing.T) {
+	var mutex sync.Mutex
+	probes := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/crowlet-not-found-") {
+			mutex.Lock()
+			probes[r.Header.Get("Accept-Language")]++
+			mutex.Unlock()
+		}
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/product" {
+			w.Write([]byte(`<a href="/deleted-accessory">Accessory</a>` + productPage))
+			return
+		}
+		w.Write([]byte(strings.Replace(notFoundPage, "Oops", "Oops "+r.URL.Path, 1)))
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:      2,
+		HTTP:          HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter:    &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		DetectSoft404: true,
+		Spider:        SpiderConfig{MaxDepth: 1},
+		Variants: []Variant{
+			{Name: "en", Header: http.Header{"Accept-Language": {"en"}}},
+			{Name: "fr", Header: http.Header{"Accept-Language": {"fr"}}},
+		},
+	}
+	crawler := NewCrawler(config)
+
+	var stats CrawlStats
+	for i := 0; i < 2; i++ {
+		iterationStats, _ := crawler.Crawl([]string{server.URL + "/product"}, make(chan struct{}))
+		stats = MergeCrawlStats(stats, iterationStats)
+	}
+
+	if len(probes) != 2 || probes["en"] != 1 || probes["fr"] != 1 {
+		t.Errorf("expected hosts to be probed once per variant, got %v", probes)
+	}
+	if len(stats.Soft404Urls) != 0 {
+		t.Errorf("expected spidered pages not to be checked for soft-404, got %v", stats.Soft404Urls)
+	}
+	if stats.Total != 8 {
+		t.Errorf("expected linked page to be crawled, got %d pages", stats.Total)
+	}
+}
@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SpiderConfig holds the limits of a recursive crawl, following hyperlinks
+// from the sitemap pages to pages of the same origin
+type SpiderConfig struct {
+	// MaxDepth is the number of links followed from sitemap pages. The
+	// crawl is not recursive if 0.
+	MaxDepth int
+	// MaxPages is the maximum number of pages crawled, including sitemap
+	// pages. No limit applies if 0.
+	MaxPages int
+}
+
+// IsEnabled returns whether pages are crawled recursively
+func (config SpiderConfig) IsEnabled() bool {
+	return config.MaxDepth > 0
+}
+
+func origin(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Scheme + "://" + parsedURL.Host
+}
+
+// spiderLinks returns the URLs of same-origin hyperlinks of the pages
+// passed which were not visited yet, in breadth-first order. Nofollow
+// hyperlinks are skipped if they must be respected. Visited URLs
+// and the pages linking to them are updated accordingly.
+func spiderLinks(pages []string, results map[string]*HTTPResponse, origins map[string]bool,
+	visited map[string]bool, linkingURLs map[string][]string, respectNoFollow bool) (frontier []string) {
+	for _, page := range pages {
+		result, ok := results[page]
+		if !ok {
+			continue
+		}
+
+		for _, link := range result.Links {
+			if link.Type != Hyperlink || (respectNoFollow && link.NoFollow) {
+				continue
+			}
+
+			target := link.TargetURL
+			if (target.Scheme != "http" && target.Scheme != "https") ||
+				!origins[target.Scheme+"://"+target.Host] {
+				continue
+			}
+
+			targetURL := spiderURL(target)
+			if visited[targetURL] {
+				if _, discovered := linkingURLs[targetURL]; discovered {
+					linkingURLs[targetURL] = appendUnique(linkingURLs[targetURL], page)
+				}
+				continue
+			}
+
+			visited[targetURL] = true
+			linkingURLs[targetURL] = []string{page}
+			frontier = append(frontier, targetURL)
+		}
+	}
+
+	return
+}
+
+// spiderURL returns the URL passed without fragment and with a root path
+// if empty, so that equivalent sitemap URLs and link targets are crawled once
+func spiderURL(target url.URL) string {
+	target.Fragment = ""
+	target.RawFragment = ""
+	if target.Path == "" && target.Opaque == "" {
+		target.Path = "/"
+		target.RawPath = ""
+	}
+	return target.String()
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// crawlSpider crawls the URLs passed, then the same-origin pages they link
+// to, breadth-first and level by level, up to the depth and number of pages
+// configured
+func crawlSpider(urls []string, config CrawlConfig, quit <-chan struct{}) (results map[string]*HTTPResponse,
+	stats CrawlStats, server200TimeSum time.Duration) {
+	results = make(map[string]*HTTPResponse)
+	visited := make(map[string]bool)
+	origins := make(map[string]bool)
+	linkingURLs := make(map[string][]string)
+	for _, rawURL := range urls {
+		if parsedURL, err := url.Parse(rawURL); err == nil {
+			visited[spiderURL(*parsedURL)] = true
+		}
+		visited[rawURL] = true
+		origins[origin(rawURL)] = true
+	}
+
+	level := urls
+	for depth := 0; len(level) > 0; depth++ {
+		levelConfig := config
+		levelConfig.HTTP.ParseLinks = config.HTTP.ParseLinks || depth < config.Spider.MaxDepth
+		if depth > 0 {
+			// Only sitemap pages are checked for soft-404 pages
+			levelConfig.HTTP.Fingerprint = false
+			levelConfig.notFoundTemplates = nil
+			log.Info("Crawling ", len(level), " page(s) found at depth ", depth)
+		}
+
+		levelResults, levelStats, levelServer200TimeSum := crawlUrls(level, levelConfig, quit)
+		stats = MergeCrawlStats(stats, levelStats)
+		server200TimeSum += levelServer200TimeSum
+		for url, result := range levelResults {
+			results[url] = result
+		}
+
+		select {
+		case <-quit:
+			return
+		default:
+		}
+
+		if depth == config.Spider.MaxDepth {
+			break
+		}
+
+		frontier := spiderLinks(level, results, origins, visited, linkingURLs, config.Links.RespectNoFollow)
+		if config.Spider.MaxPages > 0 {
+			remaining := config.Spider.MaxPages - len(results)
+			if remaining <= 0 {
+				log.Info("Maximum number of pages reached")
+				break
+			}
+			if len(frontier) > remaining {
+				log.Info("Maximum number of pages reached, ", len(frontier)-remaining, " page(s) skipped")
+				frontier = frontier[:remaining]
+			}
+		}
+
+		stats.Discovered += len(frontier)
+		level = frontier
+	}
+
+	setStatsLinkingURLs(stats, linkingURLs)
+
+	return
+}
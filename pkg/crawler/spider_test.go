@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncCrawlSpider(t *testing.T) {
+	var mutex sync.Mutex
+	requests := make(map[string]int)
+	pages := map[string]string{
+		"/":              `<a href="/products">Products</a><a href="/about#team">About</a><a href="https://example.com/">Out</a>`,
+		"/products":      `<a href="/products/blue">Blue</a><a href="/">Home</a><a href="mailto:shop@foo.bar">Mail</a>`,
+		"/about":         `<a href="/deleted">Deleted</a><a href="/products">Products</a>`,
+		"/products/blue": `<a href="/products/blue/reviews">Reviews</a>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		mutex.Unlock()
+
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body>", page, "</body></html>")
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		Spider:     SpiderConfig{MaxDepth: 2},
+	}
+
+	stats, _ := AsyncCrawl([]string{server.URL + "/"}, config, make(chan struct{}))
+
+	if stats.Total != 5 || stats.Discovered != 4 {
+		t.Errorf("expected 5 pages crawled with 4 discovered, got %d and %d", stats.Total, stats.Discovered)
+	}
+	for path, count := range requests {
+		if count != 1 {
+			t.Errorf("expected %s to be crawled once, got %d", path, count)
+		}
+	}
+	if requests["/products/blue/reviews"] != 0 {
+		t.Errorf("expected pages beyond maximum depth not to be crawled")
+	}
+
+	if len(stats.UnexpectedStatuses) != 1 {
+		t.Fatalf("expected only deleted page to be reported, got %v", stats.UnexpectedStatuses)
+	}
+	deleted := stats.UnexpectedStatuses[0]
+	if deleted.URL != server.URL+"/deleted" || len(deleted.LinkingURLs) != 1 ||
+		deleted.LinkingURLs[0] != server.URL+"/about" {
+		t.Errorf("unexpected deleted page report %+v", deleted)
+	}
+
+	requests = make(map[string]int)
+	stats, _ = AsyncCrawl([]string{server.URL}, config, make(chan struct{}))
+	if stats.Total != 5 || requests["/"] != 1 {
+		t.Errorf("expected the sitemap page without path to be crawled once, got %d pages and %d requests",
+			stats.Total, requests["/"])
+	}
+
+	config.Spider.MaxPages = 3
+	stats, _ = AsyncCrawl([]string{server.URL + "/"}, config, make(chan struct{}))
+	if stats.Total != 3 {
+		t.Errorf("expected crawl to stop after 3 pages, got %d", stats.Total)
+	}
+}
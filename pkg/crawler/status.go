@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type statusRange struct {
+	min int
+	max int
+}
+
+// StatusSet is a set of HTTP status codes, described as a comma separated
+// list of codes ("304"), classes ("2xx") or ranges ("301-308")
+type StatusSet struct {
+	description string
+	ranges      []statusRange
+}
+
+// ParseStatusSet converts a status set description such as "2xx,304" to a
+// StatusSet
+func ParseStatusSet(value string) (set StatusSet, err error) {
+	set.description = value
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+
+		var codeRange statusRange
+		if len(item) == 3 && strings.HasSuffix(item, "xx") {
+			class, err := strconv.Atoi(item[:1])
+			if err != nil || class < 1 || class > 5 {
+				return set, fmt.Errorf("invalid status class '%s'", item)
+			}
+			codeRange = statusRange{min: class * 100, max: class*100 + 99}
+		} else if bounds := strings.SplitN(item, "-", 2); len(bounds) == 2 {
+			codeRange.min, err = strconv.Atoi(bounds[0])
+			if err == nil {
+				codeRange.max, err = strconv.Atoi(bounds[1])
+			}
+			if err != nil || codeRange.min > codeRange.max {
+				return set, fmt.Errorf("invalid status range '%s'", item)
+			}
+		} else {
+			codeRange.min, err = strconv.Atoi(item)
+			if err != nil {
+				return set, fmt.Errorf("invalid status code '%s'", item)
+			}
+			codeRange.max = codeRange.min
+		}
+
+		set.ranges = append(set.ranges, codeRange)
+	}
+
+	if set.IsEmpty() {
+		return set, fmt.Errorf("empty status set '%s'", value)
+	}
+
+	return set, nil
+}
+
+// IsEmpty returns whether the set does not contain any status code
+func (set StatusSet) IsEmpty() bool {
+	return len(set.ranges) == 0
+}
+
+// Contains returns whether the status code is part of the set
+func (set StatusSet) Contains(code int) bool {
+	for _, codeRange := range set.ranges {
+		if code >= codeRange.min && code <= codeRange.max {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (set StatusSet) String() string {
+	return set.description
+}
+
+// StatusExpectation holds the status codes accepted for URLs matching
+// Pattern, a regular expression
+type StatusExpectation struct {
+	Pattern string `json:"pattern"`
+	Accept  string `json:"accept"`
+
+	pattern *regexp.Regexp
+	accept  StatusSet
+}
+
+// Compile validates the expectation and parses its pattern and status set.
+// It must be called before the expectation is used.
+func (expectation *StatusExpectation) Compile() (err error) {
+	expectation.pattern, err = regexp.Compile(expectation.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid expectation pattern '%s': %v", expectation.Pattern, err)
+	}
+
+	expectation.accept, err = ParseStatusSet(expectation.Accept)
+	if err != nil {
+		return fmt.Errorf("invalid accepted statuses for pattern '%s': %v", expectation.Pattern, err)
+	}
+	return nil
+}
+
+// LoadStatusExpectations reads and compiles a list of status expectations
+// from a JSON file
+func LoadStatusExpectations(path string) (expectations []StatusExpectation, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &expectations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expectations file '%s': %v", path, err)
+	}
+
+	for i := range expectations {
+		err = expectations[i].Compile()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// StatusPolicy decides which status codes are expected for crawled URLs.
+// The first expectation matching a URL applies, or Accept otherwise. Only
+// status 200 is accepted if Accept is empty.
+type StatusPolicy struct {
+	Accept       StatusSet
+	Expectations []StatusExpectation
+}
+
+// IsExpected returns whether the status code is acceptable for the URL
+func (policy StatusPolicy) IsExpected(url string, code int) bool {
+	if code == 0 {
+		return false
+	}
+
+	for _, expectation := range policy.Expectations {
+		if expectation.pattern != nil && expectation.pattern.MatchString(url) {
+			return expectation.accept.Contains(code)
+		}
+	}
+
+	if policy.Accept.IsEmpty() {
+		return code == 200
+	}
+
+	return policy.Accept.Contains(code)
+}
@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStatusSet(t *testing.T) {
+	tests := []struct {
+		value       string
+		contained   []int
+		excluded    []int
+		expectError bool
+	}{
+		{value: "200", contained: []int{200}, excluded: []int{201, 304}},
+		{value: "2xx,304", contained: []int{200, 204, 299, 304}, excluded: []int{301, 404}},
+		{value: "301-308, 410", contained: []int{301, 302, 308, 410}, excluded: []int{300, 309, 404}},
+		{value: "", expectError: true},
+		{value: " , ", expectError: true},
+		{value: "9xx", expectError: true},
+		{value: "308-301", expectError: true},
+		{value: "ok", expectError: true},
+	}
+
+	for _, tt := range tests {
+		set, err := ParseStatusSet(tt.value)
+		if (err != nil) != tt.expectError {
+			t.Errorf("%s: expected error: %v, got: %v", tt.value, tt.expectError, err)
+			continue
+		}
+		for _, code := range tt.contained {
+			if !set.Contains(code) {
+				t.Errorf("%s: expected %d to be contained", tt.value, code)
+			}
+		}
+		for _, code := range tt.excluded {
+			if set.Contains(code) {
+				t.Errorf("%s: expected %d not to be contained", tt.value, code)
+			}
+		}
+	}
+}
+
+func TestStatusPolicyIsExpected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expectations.json")
+	content := `[{"pattern": "/old-", "accept": "301,410"}, {"pattern": "/api/ping$", "accept": "204"}]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	expectations, err := LoadStatusExpectations(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	accept, _ := ParseStatusSet("2xx,304")
+	tests := []struct {
+		name     string
+		policy   StatusPolicy
+		url      string
+		code     int
+		expected bool
+	}{
+		{name: "Default accepts 200", url: "http://a/page", code: 200, expected: true},
+		{name: "Default rejects 204", url: "http://a/page", code: 204, expected: false},
+		{name: "Default rejects errors", url: "http://a/page", code: 0, expected: false},
+		{name: "Global accept", policy: StatusPolicy{Accept: accept}, url: "http://a/page", code: 304, expected: true},
+		{name: "Expectation accepts", policy: StatusPolicy{Expectations: expectations}, url: "http://a/old-page", code: 410, expected: true},
+		{name: "Expectation rejects 200", policy: StatusPolicy{Accept: accept, Expectations: expectations}, url: "http://a/old-page", code: 200, expected: false},
+		{name: "Second expectation", policy: StatusPolicy{Expectations: expectations}, url: "http://a/api/ping", code: 204, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if expected := tt.policy.IsExpected(tt.url, tt.code); expected != tt.expected {
+				t.Errorf("expected %v for %s with status %d, got %v", tt.expected, tt.url, tt.code, expected)
+			}
+		})
+	}
+}
+
+func TestLoadStatusExpectationsRejectsEmptySet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expectations.json")
+	if err := os.WriteFile(path, []byte(`[{"pattern": "/old-"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadStatusExpectations(path); err == nil {
+		t.Errorf("expected an error for an expectation without accepted statuses")
+	}
+}
+
+func TestMergeUnexpectedStatuses(t *testing.T) {
+	stats := CrawlStats{
+		Total:              2,
+		ExpectedStatuses:   1,
+		UnexpectedStatuses: []CrawlResult{{URL: "http://example.com/deleted", StatusCode: 404}},
+	}
+	merged := MergeCrawlStats(stats, stats)
+	if len(merged.UnexpectedStatuses) != 1 {
+		t.Errorf("expected unexpected statuses to be listed once across iterations, got %+v",
+			merged.UnexpectedStatuses)
+	}
+	if results := EvaluatePolicy([]PolicyRule{{Metric: "error-rate"}}, merged); results[0].Value != 50 {
+		t.Errorf("expected error rate to count responses, got %v", results[0].Value)
+	}
+}
@@ -0,0 +1,48 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig holds the connection pooling settings of the HTTP
+// transport shared by all crawling workers
+type TransportConfig struct {
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DisableKeepAlives   bool
+	DisableHTTP2        bool
+	DisableCompression  bool
+}
+
+// NewTransport creates an HTTP transport configured according to the
+// TransportConfig passed. A single transport is meant to be shared across
+// workers and iterations, so that connections can be reused.
+func NewTransport(config TransportConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !config.DisableHTTP2,
+		MaxIdleConns:          0,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableKeepAlives:     config.DisableKeepAlives,
+		DisableCompression:    config.DisableCompression,
+	}
+
+	if config.DisableHTTP2 {
+		// A non-nil empty map disables HTTP/2 negotiation
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+
+	return transport
+}
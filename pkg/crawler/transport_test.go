@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewTransport(t *testing.T) {
+	transport := NewTransport(TransportConfig{
+		MaxIdleConnsPerHost: 7,
+		IdleConnTimeout:     3 * time.Second,
+		DisableKeepAlives:   true,
+		DisableHTTP2:        true,
+		DisableCompression:  true,
+	})
+
+	if transport.MaxIdleConnsPerHost != 7 {
+		t.Errorf("expected 7 idle connections per host, got %d", transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 3*time.Second {
+		t.Errorf("expected idle timeout of 3s, got %v", transport.IdleConnTimeout)
+	}
+	if !transport.DisableKeepAlives || !transport.DisableCompression {
+		t.Errorf("expected keep-alive and compression to be disabled")
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Errorf("expected HTTP/2 to be disabled")
+	}
+}
+
+func TestCrawlerReusesConnections(t *testing.T) {
+	tests := []struct {
+		name             string
+		transport        TransportConfig
+		closeIdle        bool
+		expectedNewConns int
+	}{
+		{
+			name:             "Keep-alive across iterations",
+			transport:        TransportConfig{},
+			expectedNewConns: 1,
+		},
+		{
+			name:             "Idle connections closed between iterations",
+			transport:        TransportConfig{},
+			closeIdle:        true,
+			expectedNewConns: 2,
+		},
+		{
+			name:             "Keep-alive disabled",
+			transport:        TransportConfig{DisableKeepAlives: true},
+			expectedNewConns: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			newConns := 0
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					mutex.Lock()
+					newConns++
+					mutex.Unlock()
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			crawler := NewCrawler(CrawlConfig{
+				Throttle:   1,
+				HTTP:       HTTPConfig{Timeout: 5 * time.Second},
+				HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+				Transport:  tt.transport,
+			})
+
+			urls := []string{server.URL + "/a", server.URL + "/b"}
+			for i := 0; i < 2; i++ {
+				if i != 0 && tt.closeIdle {
+					crawler.CloseIdleConnections()
+				}
+				stats, err := crawler.Crawl(urls, make(chan struct{}))
+				if err != nil || stats.StatusCodes[200] != 2 {
+					t.Fatalf("unexpected crawl result: %v, %v", stats.StatusCodes, err)
+				}
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if newConns != tt.expectedNewConns {
+				t.Errorf("expected %d new connections, got %d", tt.expectedNewConns, newConns)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VariantHeader holds the values of a request header for which each URL is
+// crawled, such as the encodings or languages a CDN caches separately
+type VariantHeader struct {
+	Name   string
+	Values []string
+}
+
+// Variant is a combination of request headers, crawled as a distinct cache
+// variant
+type Variant struct {
+	Name   string
+	Header http.Header
+}
+
+// VariantStats holds the crawling statistics of a single variant
+type VariantStats struct {
+	Total          int
+	StatusCodes    map[int]int
+	Unexpected     int
+	Average200Time time.Duration
+	Max200Time     time.Duration
+}
+
+// ParseVariantHeader converts a description such as "Accept-Encoding=gzip|br"
+// to a VariantHeader
+func ParseVariantHeader(value string) (header VariantHeader, err error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return header, fmt.Errorf("invalid variant header '%s', expected 'Name=value1|value2'", value)
+	}
+
+	header.Name = http.CanonicalHeaderKey(strings.TrimSpace(parts[0]))
+	for _, headerValue := range strings.Split(parts[1], "|") {
+		header.Values = append(header.Values, strings.TrimSpace(headerValue))
+	}
+
+	return header, nil
+}
+
+// VariantMatrix returns all combinations of the header values passed
+func VariantMatrix(headers []VariantHeader) []Variant {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	variants := []Variant{{Header: http.Header{}}}
+	for _, header := range headers {
+		combined := make([]Variant, 0, len(variants)*len(header.Values))
+		for _, variant := range variants {
+			for _, value := range header.Values {
+				combinedHeader := variant.Header.Clone()
+				combinedHeader.Set(header.Name, value)
+
+				name := header.Name + "=" + value
+				if variant.Name != "" {
+					name = variant.Name + ", " + name
+				}
+				combined = append(combined, Variant{Name: name, Header: combinedHeader})
+			}
+		}
+		variants = combined
+	}
+
+	return variants
+}
+
+func newVariantStats(stats CrawlStats) VariantStats {
+	return VariantStats{
+		Total:          stats.Total,
+		StatusCodes:    stats.StatusCodes,
+		Unexpected:     len(stats.UnexpectedStatuses),
+		Average200Time: stats.Average200Time,
+		Max200Time:     stats.Max200Time,
+	}
+}
+
+func mergeVariantStats(statsA, statsB VariantStats) VariantStats {
+	merged := newVariantStats(MergeCrawlStats(
+		CrawlStats{Total: statsA.Total, StatusCodes: statsA.StatusCodes,
+			Average200Time: statsA.Average200Time, Max200Time: statsA.Max200Time},
+		CrawlStats{Total: statsB.Total, StatusCodes: statsB.StatusCodes,
+			Average200Time: statsB.Average200Time, Max200Time: statsB.Max200Time}))
+	merged.Unexpected = statsA.Unexpected + statsB.Unexpected
+
+	return merged
+}
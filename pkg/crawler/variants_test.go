@@ -0,0 +1,149 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestParseVariantHeader(t *testing.T) {
+	header, err := ParseVariantHeader("accept-encoding=gzip| br")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Name != "Accept-Encoding" || len(header.Values) != 2 || header.Values[1] != "br" {
+		t.Errorf("unexpected variant header %+v", header)
+	}
+
+	for _, value := range []string{"", "Accept-Encoding", "=gzip"} {
+		if _, err := ParseVariantHeader(value); err == nil {
+			t.Errorf("expected '%s' to be invalid", value)
+		}
+	}
+}
+
+func TestVariantMatrix(t *testing.T) {
+	variants := VariantMatrix([]VariantHeader{
+		{Name: "Accept-Encoding", Values: []string{"gzip", "br"}},
+		{Name: "X-Device", Values: []string{"mobile", "desktop"}},
+	})
+
+	if len(variants) != 4 {
+		t.Fatalf("expected 4 variants, got %d", len(variants))
+	}
+	if variants[0].Name != "Accept-Encoding=gzip, X-Device=mobile" {
+		t.Errorf("unexpected variant name '%s'", variants[0].Name)
+	}
+	last := variants[3].Header
+	if last.Get("Accept-Encoding") != "br" || last.Get("X-Device") != "desktop" {
+		t.Errorf("unexpected variant headers %v", last)
+	}
+	if VariantMatrix(nil) != nil {
+		t.Errorf("expected no variant without headers")
+	}
+}
+
+func TestAsyncCrawlVariants(t *testing.T) {
+	var mutex sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.Header.Get("Accept-Encoding")+" "+r.Header.Get("X-Device")]++
+		mutex.Unlock()
+
+		if r.Header.Get("X-Device") == "mobile" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := CrawlConfig{
+		Throttle:   2,
+		HTTP:       HTTPConfig{Timeout: 5 * time.Second},
+		HTTPGetter: &BaseConcurrentHTTPGetter{Get: HTTPGet},
+		Variants: VariantMatrix([]VariantHeader{
+			{Name: "Accept-Encoding", Values: []string{"gzip", "br"}},
+			{Name: "X-Device", Values: []string{"mobile", "desktop"}},
+		}),
+	}
+
+	stats, _ := AsyncCrawl([]string{server.URL + "/a", server.URL + "/b"}, config, make(chan struct{}))
+
+	if stats.Total != 8 {
+		t.Errorf("expected each URL to be crawled once per variant, got %d crawled", stats.Total)
+	}
+	for key, count := range requests {
+		if count != 2 {
+			t.Errorf("expected 2 requests with headers '%s', got %d", key, count)
+		}
+	}
+	if len(stats.Variants) != 4 {
+		t.Fatalf("expected stats for 4 variants, got %d", len(stats.Variants))
+	}
+
+	mobile := stats.Variants["Accept-Encoding=br, X-Device=mobile"]
+	if mobile.Total != 2 || mobile.Unexpected != 2 || mobile.StatusCodes[404] != 2 {
+		t.Errorf("unexpected mobile variant stats %+v", mobile)
+	}
+	desktop := stats.Variants["Accept-Encoding=gzip, X-Device=desktop"]
+	if desktop.Total != 2 || desktop.Unexpected != 0 || desktop.StatusCodes[200] != 2 {
+		t.Errorf("unexpected desktop variant stats %+v", desktop)
+	}
+
+	merged := MergeCrawlStats(stats, stats)
+	if merged.Variants["Accept-Encoding=gzip, X-Device=desktop"].Total != 4 {
+		t.Errorf("expected variant stats to be merged across iterations")
+	}
+}
+
+func TestDecodeContent(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	writer.Write([]byte("<html>compressed</html>"))
+	writer.Close()
+
+	content, err := decodeContent(buffer.Bytes(), "gzip")
+	if err != nil || string(content) != "<html>compressed</html>" {
+		t.Errorf("unexpected decoded content '%s' (%v)", content, err)
+	}
+
+	buffer.Reset()
+	brotliWriter := brotli.NewWriter(&buffer)
+	brotliWriter.Write([]byte("<html>compressed</html>"))
+	brotliWriter.Close()
+
+	content, err = decodeContent(buffer.Bytes(), "br")
+	if err != nil || string(content) != "<html>compressed</html>" {
+		t.Errorf("unexpected decoded br content '%s' (%v)", content, err)
+	}
+
+	if _, err := decodeContent(buffer.Bytes(), "zstd"); err == nil {
+		t.Errorf("expected unsupported encoding to fail")
+	}
+}
+
+func TestHTTPGetReportsUndecodableBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "zstd")
+		w.Write([]byte("not really compressed"))
+	}))
+	defer server.Close()
+
+	config := HTTPConfig{
+		Timeout:    5 * time.Second,
+		Headers:    http.Header{"Accept-Encoding": {"zstd"}},
+		Assertions: []Assertion{{Contains: []string{"compressed"}}},
+	}
+	response := HTTPGet(&http.Client{}, server.URL, config)
+	if len(response.AssertionFailures) != 1 ||
+		!strings.HasPrefix(response.AssertionFailures[0], "body can not be decoded") {
+		t.Errorf("expected undecodable body to fail assertions, got %v", response.AssertionFailures)
+	}
+}